@@ -0,0 +1,14 @@
+package group
+
+# デフォルトでは類似度（--group-threshold）のみで集約
+default match = false
+
+# 1時間以内に同じIPアドレスが現れたアラートは同一事象として集約
+match if {
+	input.elapsed < 3600
+	some a in input.alert.attributes
+	a.type == "ip_address"
+	some c in input.candidate.attributes
+	c.type == "ip_address"
+	a.value == c.value
+}
@@ -0,0 +1,134 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// fileStorage implements Storage interface using a local directory
+type fileStorage struct {
+	dir    string
+	prefix string
+}
+
+// NewFileStorage creates a new Storage backed by files under dir
+func NewFileStorage(dir string, opts ...StorageOption) (Storage, error) {
+	if dir == "" {
+		return nil, goerr.New("storage directory is required")
+	}
+
+	var o storageOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, goerr.Wrap(err, "failed to create storage directory", goerr.Value("dir", dir))
+	}
+
+	return &fileStorage{
+		dir:    dir,
+		prefix: o.prefix,
+	}, nil
+}
+
+func (s *fileStorage) Put(ctx context.Context, key string) (io.WriteCloser, error) {
+	path, err := s.buildFilePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, goerr.Wrap(err, "failed to create storage directory", goerr.Value("key", key))
+	}
+
+	// Same as Cloud Storage, the object becomes visible only after Close succeeds
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create temporary file", goerr.Value("key", key))
+	}
+
+	return &fileWriter{
+		ctx:  ctx,
+		file: tmp,
+		path: path,
+	}, nil
+}
+
+func (s *fileStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.buildFilePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, goerr.Wrap(err, "object not found in storage", goerr.Value("key", key))
+		}
+		return nil, goerr.Wrap(err, "failed to read from storage", goerr.Value("key", key))
+	}
+
+	return file, nil
+}
+
+// buildFilePath converts an object key to a file path and rejects keys escaping the storage directory
+func (s *fileStorage) buildFilePath(key string) (string, error) {
+	objectKey := s.prefix + key
+	path := filepath.Join(s.dir, filepath.FromSlash(objectKey))
+
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", goerr.New("invalid storage key", goerr.Value("key", key))
+	}
+
+	return path, nil
+}
+
+// fileWriter writes to a temporary file and moves it to the final path on Close
+type fileWriter struct {
+	ctx    context.Context
+	file   *os.File
+	path   string
+	closed bool
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, goerr.Wrap(err, "context canceled while writing to storage")
+	}
+	return w.file.Write(p)
+}
+
+func (w *fileWriter) Close() error {
+	// Allow multiple Close calls, e.g. deferred Close after an explicit one
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	tmpName := w.file.Name()
+	if err := w.file.Close(); err != nil {
+		os.Remove(tmpName)
+		return goerr.Wrap(err, "failed to close storage file", goerr.Value("path", w.path))
+	}
+
+	if err := w.ctx.Err(); err != nil {
+		os.Remove(tmpName)
+		return goerr.Wrap(err, "context canceled before storage file was committed", goerr.Value("path", w.path))
+	}
+
+	if err := os.Rename(tmpName, w.path); err != nil {
+		os.Remove(tmpName)
+		return goerr.Wrap(err, "failed to commit storage file", goerr.Value("path", w.path))
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package adapter_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+)
+
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	storage, err := adapter.NewFileStorage(dir, adapter.WithPrefix("leveret/"))
+	gt.NoError(t, err)
+
+	t.Run("put and get", func(t *testing.T) {
+		w, err := storage.Put(ctx, "histories/test.json")
+		gt.NoError(t, err)
+		_, err = w.Write([]byte(`{"hello":"world"}`))
+		gt.NoError(t, err)
+
+		// Object is not visible until Close
+		_, err = os.Stat(filepath.Join(dir, "leveret", "histories", "test.json"))
+		gt.True(t, os.IsNotExist(err))
+
+		gt.NoError(t, w.Close())
+		// Second Close is allowed
+		gt.NoError(t, w.Close())
+
+		_, err = os.Stat(filepath.Join(dir, "leveret", "histories", "test.json"))
+		gt.NoError(t, err)
+
+		r, err := storage.Get(ctx, "histories/test.json")
+		gt.NoError(t, err)
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		gt.NoError(t, err)
+		gt.Equal(t, string(data), `{"hello":"world"}`)
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		_, err := storage.Get(ctx, "histories/missing.json")
+		gt.Error(t, err)
+	})
+
+	t.Run("reject key escaping directory", func(t *testing.T) {
+		_, err := storage.Put(ctx, "../../outside.json")
+		gt.Error(t, err)
+	})
+}
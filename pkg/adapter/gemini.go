@@ -2,20 +2,25 @@ package adapter
 
 import (
 	"context"
+	"iter"
 
 	"cloud.google.com/go/firestore"
 	"github.com/m-mizutani/goerr/v2"
 	"google.golang.org/genai"
 )
 
+// Gemini is the LLM interface used across leveret. GeminiClient is the native
+// implementation and OpenAIClient provides an OpenAI-compatible backend.
 type Gemini interface {
 	GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error)
+	GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error]
 	CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error)
 	Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error)
 }
 
 type GeminiClient struct {
 	client          *genai.Client
+	apiKey          string
 	generativeModel string
 	embeddingModel  string
 }
@@ -34,18 +39,16 @@ func WithEmbeddingModel(model string) GeminiOption {
 	}
 }
 
-func NewGemini(ctx context.Context, projectID, location string, opts ...GeminiOption) (*GeminiClient, error) {
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		Project:  projectID,
-		Location: location,
-		Backend:  genai.BackendVertexAI,
-	})
-	if err != nil {
-		return nil, goerr.Wrap(err, "failed to create genai client")
+// WithAPIKey switches the backend from Vertex AI to Gemini API (Google AI Studio).
+// projectID and location of NewGemini are ignored in this mode.
+func WithAPIKey(apiKey string) GeminiOption {
+	return func(g *GeminiClient) {
+		g.apiKey = apiKey
 	}
+}
 
+func NewGemini(ctx context.Context, projectID, location string, opts ...GeminiOption) (*GeminiClient, error) {
 	g := &GeminiClient{
-		client:          client,
 		generativeModel: "gemini-2.5-flash",
 		embeddingModel:  "gemini-embedding-001",
 	}
@@ -54,6 +57,24 @@ func NewGemini(ctx context.Context, projectID, location string, opts ...GeminiOp
 		opt(g)
 	}
 
+	clientConfig := &genai.ClientConfig{
+		Project:  projectID,
+		Location: location,
+		Backend:  genai.BackendVertexAI,
+	}
+	if g.apiKey != "" {
+		clientConfig = &genai.ClientConfig{
+			APIKey:  g.apiKey,
+			Backend: genai.BackendGeminiAPI,
+		}
+	}
+
+	client, err := genai.NewClient(ctx, clientConfig)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create genai client", goerr.V("backend", clientConfig.Backend))
+	}
+	g.client = client
+
 	return g, nil
 }
 
@@ -65,6 +86,20 @@ func (g *GeminiClient) GenerateContent(ctx context.Context, contents []*genai.Co
 	return resp, nil
 }
 
+func (g *GeminiClient) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for resp, err := range g.client.Models.GenerateContentStream(ctx, g.generativeModel, contents, config) {
+			if err != nil {
+				yield(nil, goerr.Wrap(err, "failed to generate content stream"))
+				return
+			}
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (g *GeminiClient) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
 	chat, err := g.client.Chats.Create(ctx, g.generativeModel, config, history)
 	if err != nil {
@@ -44,3 +44,30 @@ func TestGenerateContent(t *testing.T) {
 
 	t.Log("response:", resp.Candidates[0].Content.Parts[0].Text)
 }
+
+func TestGeminiAPIKey(t *testing.T) {
+	apiKey := os.Getenv("TEST_GEMINI_API_KEY")
+	if apiKey == "" {
+		t.Skip("TEST_GEMINI_API_KEY is not set")
+	}
+
+	ctx := context.Background()
+	client, err := adapter.NewGemini(ctx, "", "", adapter.WithAPIKey(apiKey))
+	gt.NoError(t, err)
+
+	t.Run("GenerateContent", func(t *testing.T) {
+		contents := []*genai.Content{
+			genai.NewContentFromText("Hello, what is the capital of France?", genai.RoleUser),
+		}
+
+		resp, err := client.GenerateContent(ctx, contents, nil)
+		gt.NoError(t, err)
+		gt.A(t, resp.Candidates).Longer(0)
+	})
+
+	t.Run("Embedding", func(t *testing.T) {
+		embedding, err := client.Embedding(ctx, "suspicious login from unknown IP address", 768)
+		gt.NoError(t, err)
+		gt.A(t, embedding).Length(768)
+	})
+}
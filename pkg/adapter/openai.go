@@ -0,0 +1,528 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"google.golang.org/genai"
+)
+
+// OpenAIClient implements Gemini interface on top of an OpenAI-compatible chat
+// completions API such as OpenAI, Ollama, vLLM or llama.cpp server. genai
+// request and response structures are translated to and from the OpenAI format
+// so that callers do not need to know which backend is used.
+type OpenAIClient struct {
+	baseURL         string
+	apiKey          string
+	generativeModel string
+	embeddingModel  string
+	httpClient      *http.Client
+}
+
+var _ Gemini = &OpenAIClient{}
+
+type OpenAIOption func(*OpenAIClient)
+
+func WithOpenAIGenerativeModel(model string) OpenAIOption {
+	return func(c *OpenAIClient) {
+		c.generativeModel = model
+	}
+}
+
+func WithOpenAIEmbeddingModel(model string) OpenAIOption {
+	return func(c *OpenAIClient) {
+		c.embeddingModel = model
+	}
+}
+
+func WithOpenAIAPIKey(apiKey string) OpenAIOption {
+	return func(c *OpenAIClient) {
+		c.apiKey = apiKey
+	}
+}
+
+func WithOpenAIHTTPClient(client *http.Client) OpenAIOption {
+	return func(c *OpenAIClient) {
+		c.httpClient = client
+	}
+}
+
+// NewOpenAI creates a new client for an OpenAI-compatible API. baseURL is the
+// API root including version, e.g. "https://api.openai.com/v1" or
+// "http://localhost:11434/v1" for Ollama.
+func NewOpenAI(baseURL string, opts ...OpenAIOption) (*OpenAIClient, error) {
+	if baseURL == "" {
+		return nil, goerr.New("base URL is required")
+	}
+
+	c := &OpenAIClient{
+		baseURL:         strings.TrimRight(baseURL, "/"),
+		generativeModel: "gpt-4o-mini",
+		embeddingModel:  "text-embedding-3-small",
+		httpClient:      http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// OpenAI API request/response structures (only fields used by leveret)
+
+type openaiMessage struct {
+	Role       string           `json:"role"`
+	Content    *string          `json:"content"`
+	ToolCalls  []openaiToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openaiToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function openaiFunctionCall `json:"function"`
+}
+
+type openaiFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type openaiTool struct {
+	Type     string             `json:"type"`
+	Function openaiFunctionSpec `json:"function"`
+}
+
+type openaiFunctionSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+type openaiResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openaiJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openaiJSONSchema struct {
+	Name   string `json:"name"`
+	Schema any    `json:"schema"`
+}
+
+type openaiChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openaiMessage       `json:"messages"`
+	Tools          []openaiTool          `json:"tools,omitempty"`
+	ResponseFormat *openaiResponseFormat `json:"response_format,omitempty"`
+	Temperature    *float32              `json:"temperature,omitempty"`
+	TopP           *float32              `json:"top_p,omitempty"`
+	MaxTokens      int32                 `json:"max_tokens,omitempty"`
+	Stop           []string              `json:"stop,omitempty"`
+	Seed           *int32                `json:"seed,omitempty"`
+}
+
+type openaiChatResponse struct {
+	Choices []struct {
+		Index        int           `json:"index"`
+		Message      openaiMessage `json:"message"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int32 `json:"prompt_tokens"`
+		CompletionTokens int32 `json:"completion_tokens"`
+		TotalTokens      int32 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+type openaiEmbeddingRequest struct {
+	Model      string `json:"model"`
+	Input      string `json:"input"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+type openaiEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (c *OpenAIClient) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	req, err := buildOpenAIChatRequest(c.generativeModel, contents, config)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to build chat completion request")
+	}
+
+	var resp openaiChatResponse
+	if err := c.post(ctx, "/chat/completions", req, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to generate content")
+	}
+
+	result, err := convertOpenAIChatResponse(&resp)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to convert chat completion response")
+	}
+	return result, nil
+}
+
+// GenerateContentStream does not use server-sent events of the OpenAI API. The
+// whole response is yielded as a single chunk so that callers can use the same
+// code path for all backends.
+func (c *OpenAIClient) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		yield(c.GenerateContent(ctx, contents, config))
+	}
+}
+
+// CreateChat is not supported because genai.Chat is bound to the genai client
+func (c *OpenAIClient) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
+	return nil, goerr.New("CreateChat is not supported by OpenAI-compatible backend")
+}
+
+func (c *OpenAIClient) Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error) {
+	req := &openaiEmbeddingRequest{
+		Model:      c.embeddingModel,
+		Input:      text,
+		Dimensions: dimensions,
+	}
+
+	var resp openaiEmbeddingResponse
+	if err := c.post(ctx, "/embeddings", req, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to embed content")
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, goerr.New("no embeddings returned")
+	}
+
+	return firestore.Vector32(resp.Data[0].Embedding), nil
+}
+
+func (c *OpenAIClient) post(ctx context.Context, path string, reqBody, respBody any) error {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return goerr.Wrap(err, "failed to create HTTP request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send HTTP request", goerr.V("path", path))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return goerr.Wrap(err, "failed to read HTTP response", goerr.V("path", path))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// Surface as genai.APIError so that callers can handle errors in the same way as Gemini
+		apiErr := genai.APIError{
+			Code:    resp.StatusCode,
+			Status:  http.StatusText(resp.StatusCode),
+			Message: string(data),
+		}
+		return goerr.Wrap(apiErr, "unexpected HTTP status", goerr.V("path", path), goerr.V("status", resp.StatusCode))
+	}
+
+	if err := json.Unmarshal(data, respBody); err != nil {
+		return goerr.Wrap(err, "failed to unmarshal HTTP response", goerr.V("path", path), goerr.V("body", string(data)))
+	}
+
+	return nil
+}
+
+// buildOpenAIChatRequest converts genai contents and config to a chat completion request
+func buildOpenAIChatRequest(model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*openaiChatRequest, error) {
+	req := &openaiChatRequest{
+		Model: model,
+	}
+
+	if config != nil {
+		if text := joinTextParts(config.SystemInstruction); text != "" {
+			req.Messages = append(req.Messages, openaiMessage{Role: "system", Content: &text})
+		}
+
+		req.Temperature = config.Temperature
+		req.TopP = config.TopP
+		req.MaxTokens = config.MaxOutputTokens
+		req.Stop = config.StopSequences
+		req.Seed = config.Seed
+
+		for _, t := range config.Tools {
+			for _, fd := range t.FunctionDeclarations {
+				spec := openaiFunctionSpec{
+					Name:        fd.Name,
+					Description: fd.Description,
+				}
+				switch {
+				case fd.ParametersJsonSchema != nil:
+					spec.Parameters = fd.ParametersJsonSchema
+				case fd.Parameters != nil:
+					spec.Parameters = genaiSchemaToJSONSchema(fd.Parameters)
+				default:
+					spec.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+				}
+				req.Tools = append(req.Tools, openaiTool{Type: "function", Function: spec})
+			}
+		}
+
+		if config.ResponseMIMEType == "application/json" {
+			switch {
+			case config.ResponseJsonSchema != nil:
+				req.ResponseFormat = &openaiResponseFormat{
+					Type:       "json_schema",
+					JSONSchema: &openaiJSONSchema{Name: "response", Schema: config.ResponseJsonSchema},
+				}
+			case config.ResponseSchema != nil:
+				req.ResponseFormat = &openaiResponseFormat{
+					Type:       "json_schema",
+					JSONSchema: &openaiJSONSchema{Name: "response", Schema: genaiSchemaToJSONSchema(config.ResponseSchema)},
+				}
+			default:
+				req.ResponseFormat = &openaiResponseFormat{Type: "json_object"}
+			}
+		}
+	}
+
+	// genai allows function calls and responses without ID. OpenAI requires an ID to
+	// pair them, so assign IDs to calls and match responses by function name in order.
+	pending := make(map[string][]string)
+	callCount := 0
+
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+
+		switch content.Role {
+		case genai.RoleModel:
+			msg := openaiMessage{Role: "assistant"}
+			if text := joinTextParts(content); text != "" {
+				msg.Content = &text
+			}
+			for _, part := range content.Parts {
+				if part.FunctionCall == nil {
+					continue
+				}
+				id := part.FunctionCall.ID
+				if id == "" {
+					id = fmt.Sprintf("call_%d", callCount)
+				}
+				callCount++
+				pending[part.FunctionCall.Name] = append(pending[part.FunctionCall.Name], id)
+
+				args, err := json.Marshal(part.FunctionCall.Args)
+				if err != nil {
+					return nil, goerr.Wrap(err, "failed to marshal function call arguments", goerr.V("name", part.FunctionCall.Name))
+				}
+				msg.ToolCalls = append(msg.ToolCalls, openaiToolCall{
+					ID:       id,
+					Type:     "function",
+					Function: openaiFunctionCall{Name: part.FunctionCall.Name, Arguments: string(args)},
+				})
+			}
+			if msg.Content == nil && len(msg.ToolCalls) == 0 {
+				continue
+			}
+			req.Messages = append(req.Messages, msg)
+
+		default:
+			for _, part := range content.Parts {
+				if part.FunctionResponse == nil {
+					continue
+				}
+				fr := part.FunctionResponse
+				id := fr.ID
+				if queue := pending[fr.Name]; len(queue) > 0 {
+					if id == "" {
+						id = queue[0]
+					}
+					pending[fr.Name] = queue[1:]
+				}
+				if id == "" {
+					return nil, goerr.New("function response without matching function call", goerr.V("name", fr.Name))
+				}
+
+				result, err := json.Marshal(fr.Response)
+				if err != nil {
+					return nil, goerr.Wrap(err, "failed to marshal function response", goerr.V("name", fr.Name))
+				}
+				text := string(result)
+				req.Messages = append(req.Messages, openaiMessage{Role: "tool", ToolCallID: id, Content: &text})
+			}
+
+			if text := joinTextParts(content); text != "" {
+				req.Messages = append(req.Messages, openaiMessage{Role: "user", Content: &text})
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// convertOpenAIChatResponse converts a chat completion response to genai response
+func convertOpenAIChatResponse(resp *openaiChatResponse) (*genai.GenerateContentResponse, error) {
+	result := &genai.GenerateContentResponse{}
+
+	for _, choice := range resp.Choices {
+		content := &genai.Content{Role: genai.RoleModel}
+		if choice.Message.Content != nil && *choice.Message.Content != "" {
+			content.Parts = append(content.Parts, &genai.Part{Text: *choice.Message.Content})
+		}
+		for _, tc := range choice.Message.ToolCalls {
+			args := map[string]any{}
+			if tc.Function.Arguments != "" {
+				if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+					return nil, goerr.Wrap(err, "failed to parse tool call arguments", goerr.V("name", tc.Function.Name), goerr.V("arguments", tc.Function.Arguments))
+				}
+			}
+			content.Parts = append(content.Parts, &genai.Part{
+				FunctionCall: &genai.FunctionCall{
+					ID:   tc.ID,
+					Name: tc.Function.Name,
+					Args: args,
+				},
+			})
+		}
+
+		result.Candidates = append(result.Candidates, &genai.Candidate{
+			Index:        int32(choice.Index),
+			Content:      content,
+			FinishReason: convertOpenAIFinishReason(choice.FinishReason),
+		})
+	}
+
+	if resp.Usage != nil {
+		result.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     resp.Usage.PromptTokens,
+			CandidatesTokenCount: resp.Usage.CompletionTokens,
+			TotalTokenCount:      resp.Usage.TotalTokens,
+		}
+	}
+
+	return result, nil
+}
+
+func convertOpenAIFinishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "stop", "tool_calls", "function_call":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	case "content_filter":
+		return genai.FinishReasonSafety
+	case "":
+		return genai.FinishReasonUnspecified
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+// joinTextParts concatenates non-thought text parts of the content
+func joinTextParts(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+
+	var texts []string
+	for _, part := range content.Parts {
+		if part.Text != "" && !part.Thought {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// genaiSchemaToJSONSchema converts genai.Schema (OpenAPI subset) to JSON Schema
+func genaiSchemaToJSONSchema(schema *genai.Schema) map[string]any {
+	if schema == nil {
+		return nil
+	}
+
+	result := map[string]any{}
+
+	if schema.Type != "" && schema.Type != genai.TypeUnspecified {
+		typ := strings.ToLower(string(schema.Type))
+		if schema.Nullable != nil && *schema.Nullable {
+			result["type"] = []string{typ, "null"}
+		} else {
+			result["type"] = typ
+		}
+	}
+	if schema.Description != "" {
+		result["description"] = schema.Description
+	}
+	if schema.Title != "" {
+		result["title"] = schema.Title
+	}
+	if schema.Format != "" {
+		result["format"] = schema.Format
+	}
+	if schema.Pattern != "" {
+		result["pattern"] = schema.Pattern
+	}
+	if len(schema.Enum) > 0 {
+		result["enum"] = schema.Enum
+	}
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+	if schema.MinLength != nil {
+		result["minLength"] = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+	if schema.MinItems != nil {
+		result["minItems"] = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+	if schema.Minimum != nil {
+		result["minimum"] = *schema.Minimum
+	}
+	if schema.Maximum != nil {
+		result["maximum"] = *schema.Maximum
+	}
+	if schema.Items != nil {
+		result["items"] = genaiSchemaToJSONSchema(schema.Items)
+	}
+	if len(schema.Properties) > 0 {
+		props := make(map[string]any, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			props[name] = genaiSchemaToJSONSchema(prop)
+		}
+		result["properties"] = props
+	}
+	if len(schema.Required) > 0 {
+		result["required"] = schema.Required
+	}
+	if len(schema.AnyOf) > 0 {
+		anyOf := make([]any, 0, len(schema.AnyOf))
+		for _, s := range schema.AnyOf {
+			anyOf = append(anyOf, genaiSchemaToJSONSchema(s))
+		}
+		result["anyOf"] = anyOf
+	}
+
+	return result
+}
@@ -0,0 +1,154 @@
+package adapter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"google.golang.org/genai"
+)
+
+func TestOpenAIGenerateContent(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.URL.Path, "/v1/chat/completions")
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-key")
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"choices": [{
+				"index": 0,
+				"message": {
+					"role": "assistant",
+					"content": "checking",
+					"tool_calls": [{"id": "call_x", "type": "function", "function": {"name": "search_alerts", "arguments": "{\"field\":\"Type\"}"}}]
+				},
+				"finish_reason": "tool_calls"
+			}],
+			"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}
+		}`))
+	}))
+	defer srv.Close()
+
+	client, err := adapter.NewOpenAI(srv.URL+"/v1", adapter.WithOpenAIAPIKey("test-key"), adapter.WithOpenAIGenerativeModel("llama3"))
+	gt.NoError(t, err)
+
+	contents := []*genai.Content{
+		genai.NewContentFromText("find alerts", genai.RoleUser),
+		{
+			Role:  genai.RoleModel,
+			Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "search_alerts", Args: map[string]any{"field": "Severity"}}}},
+		},
+		{
+			Role:  genai.RoleUser,
+			Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{Name: "search_alerts", Response: map[string]any{"result": "none"}}}},
+		},
+	}
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("you are an analyst", ""),
+		Tools: []*genai.Tool{{
+			FunctionDeclarations: []*genai.FunctionDeclaration{{
+				Name:        "search_alerts",
+				Description: "Search alerts",
+				Parameters: &genai.Schema{
+					Type:       genai.TypeObject,
+					Properties: map[string]*genai.Schema{"field": {Type: genai.TypeString}},
+					Required:   []string{"field"},
+				},
+			}},
+		}},
+	}
+
+	resp, err := client.GenerateContent(context.Background(), contents, config)
+	gt.NoError(t, err)
+
+	// Request translation
+	gt.Equal(t, received["model"], "llama3")
+	messages := received["messages"].([]any)
+	gt.A(t, messages).Length(4)
+	gt.Equal(t, messages[0].(map[string]any)["role"], "system")
+	gt.Equal(t, messages[1].(map[string]any)["role"], "user")
+	assistant := messages[2].(map[string]any)
+	gt.Equal(t, assistant["role"], "assistant")
+	toolCall := assistant["tool_calls"].([]any)[0].(map[string]any)
+	toolMsg := messages[3].(map[string]any)
+	gt.Equal(t, toolMsg["role"], "tool")
+	gt.Equal(t, toolMsg["tool_call_id"], toolCall["id"])
+
+	tools := received["tools"].([]any)
+	params := tools[0].(map[string]any)["function"].(map[string]any)["parameters"].(map[string]any)
+	gt.Equal(t, params["type"], "object")
+
+	// Response translation
+	gt.A(t, resp.Candidates).Length(1)
+	parts := resp.Candidates[0].Content.Parts
+	gt.A(t, parts).Length(2)
+	gt.Equal(t, parts[0].Text, "checking")
+	gt.Equal(t, parts[1].FunctionCall.ID, "call_x")
+	gt.Equal(t, parts[1].FunctionCall.Name, "search_alerts")
+	gt.Equal(t, parts[1].FunctionCall.Args["field"], "Type")
+	gt.Equal(t, resp.UsageMetadata.TotalTokenCount, int32(15))
+}
+
+func TestOpenAIResponseSchema(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"{\"title\":\"x\"}"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := adapter.NewOpenAI(srv.URL)
+	gt.NoError(t, err)
+
+	resp, err := client.GenerateContent(context.Background(),
+		[]*genai.Content{genai.NewContentFromText("summarize", genai.RoleUser)},
+		&genai.GenerateContentConfig{
+			ResponseMIMEType: "application/json",
+			ResponseSchema: &genai.Schema{
+				Type:       genai.TypeObject,
+				Properties: map[string]*genai.Schema{"title": {Type: genai.TypeString}},
+			},
+		})
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Candidates[0].Content.Parts[0].Text, `{"title":"x"}`)
+
+	format := received["response_format"].(map[string]any)
+	gt.Equal(t, format["type"], "json_schema")
+	schema := format["json_schema"].(map[string]any)["schema"].(map[string]any)
+	gt.Equal(t, schema["type"], "object")
+}
+
+func TestOpenAIEmbedding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.URL.Path, "/embeddings")
+		_, _ = w.Write([]byte(`{"data":[{"embedding":[0.1,0.2,0.3]}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := adapter.NewOpenAI(srv.URL)
+	gt.NoError(t, err)
+
+	vec, err := client.Embedding(context.Background(), "hello", 3)
+	gt.NoError(t, err)
+	gt.A(t, vec).Length(3)
+}
+
+func TestOpenAIErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"bad request"}`))
+	}))
+	defer srv.Close()
+
+	client, err := adapter.NewOpenAI(srv.URL)
+	gt.NoError(t, err)
+
+	_, err = client.GenerateContent(context.Background(), []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)}, nil)
+	gt.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package adapter
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+var (
+	// ErrQueueClosed is returned by Receive when no more messages will be delivered
+	ErrQueueClosed = goerr.New("queue closed")
+)
+
+// Message is a message pulled from a queue
+type Message struct {
+	ID              string
+	Data            []byte
+	Attributes      map[string]string
+	DeliveryAttempt int // 1 for the first delivery
+}
+
+// Queue is a message queue with Pub/Sub-style at-least-once delivery. A
+// received message is redelivered unless it is acknowledged.
+type Queue interface {
+	// Receive blocks until a message is available
+	Receive(ctx context.Context) (*Message, error)
+
+	// Ack acknowledges a message so that it is not redelivered
+	Ack(ctx context.Context, msg *Message) error
+
+	// Nack requests redelivery of a message
+	Nack(ctx context.Context, msg *Message) error
+
+	// DeadLetter moves a message that can not be processed to the dead-letter
+	// destination. The message is acknowledged as well.
+	DeadLetter(ctx context.Context, msg *Message, reason error) error
+}
+
+// DeadLetterMessage is a message moved to the dead-letter destination of MemoryQueue
+type DeadLetterMessage struct {
+	Message *Message
+	Reason  string
+}
+
+// MemoryQueue is an in-memory Queue for local use and testing
+type MemoryQueue struct {
+	mu       sync.Mutex
+	pending  []*Message
+	inflight map[*Message]struct{}
+	dead     []*DeadLetterMessage
+	seq      int
+	closed   bool
+	notify   chan struct{}
+}
+
+var _ Queue = &MemoryQueue{}
+
+// NewMemoryQueue creates a new in-memory queue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		inflight: make(map[*Message]struct{}),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// Publish adds a message and returns its ID. If id is empty, a sequential ID
+// is assigned. Publishing with the same ID again simulates a redelivery by
+// the broker.
+func (q *MemoryQueue) Publish(id string, data []byte, attrs map[string]string) string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	if id == "" {
+		id = strconv.Itoa(q.seq)
+	}
+
+	q.pending = append(q.pending, &Message{
+		ID:         id,
+		Data:       data,
+		Attributes: attrs,
+	})
+	q.wakeup()
+
+	return id
+}
+
+// Close stops delivery. Receive returns ErrQueueClosed after pending messages are drained.
+func (q *MemoryQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.wakeup()
+}
+
+func (q *MemoryQueue) Receive(ctx context.Context) (*Message, error) {
+	for {
+		q.mu.Lock()
+		if len(q.pending) > 0 {
+			msg := q.pending[0]
+			q.pending = q.pending[1:]
+			msg.DeliveryAttempt++
+			q.inflight[msg] = struct{}{}
+			if len(q.pending) > 0 {
+				q.wakeup()
+			}
+			q.mu.Unlock()
+			return msg, nil
+		}
+		closed := q.closed
+		q.mu.Unlock()
+
+		if closed {
+			return nil, ErrQueueClosed
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-q.notify:
+		}
+	}
+}
+
+func (q *MemoryQueue) Ack(ctx context.Context, msg *Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.inflight[msg]; !ok {
+		return goerr.New("message is not in flight", goerr.V("id", msg.ID))
+	}
+	delete(q.inflight, msg)
+	q.wakeup()
+	return nil
+}
+
+func (q *MemoryQueue) Nack(ctx context.Context, msg *Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.inflight[msg]; !ok {
+		return goerr.New("message is not in flight", goerr.V("id", msg.ID))
+	}
+	delete(q.inflight, msg)
+	q.pending = append(q.pending, msg)
+	q.wakeup()
+	return nil
+}
+
+func (q *MemoryQueue) DeadLetter(ctx context.Context, msg *Message, reason error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.inflight[msg]; !ok {
+		return goerr.New("message is not in flight", goerr.V("id", msg.ID))
+	}
+	delete(q.inflight, msg)
+
+	dl := &DeadLetterMessage{Message: msg}
+	if reason != nil {
+		dl.Reason = reason.Error()
+	}
+	q.dead = append(q.dead, dl)
+	q.wakeup()
+	return nil
+}
+
+// DeadLetters returns messages moved to the dead-letter destination
+func (q *MemoryQueue) DeadLetters() []*DeadLetterMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dead := make([]*DeadLetterMessage, len(q.dead))
+	copy(dead, q.dead)
+	return dead
+}
+
+// Len returns the number of messages that are pending or in flight
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) + len(q.inflight)
+}
+
+// wakeup notifies a waiting receiver. It must be called with q.mu held.
+func (q *MemoryQueue) wakeup() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
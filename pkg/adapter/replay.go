@@ -0,0 +1,277 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"google.golang.org/genai"
+)
+
+// ReplayMode specifies how ReplayClient handles requests
+type ReplayMode string
+
+const (
+	// ReplayModeRecord forwards requests to the underlying client and saves responses as fixtures
+	ReplayModeRecord ReplayMode = "record"
+	// ReplayModeReplay serves responses from fixtures without calling any backend
+	ReplayModeReplay ReplayMode = "replay"
+)
+
+var (
+	ErrFixtureNotFound = goerr.New("fixture not found")
+)
+
+// ReplayClient is a Gemini decorator that records request/response pairs to
+// fixture files and replays them later. Each fixture is stored as
+// <dir>/<kind>-<sha256 of request>.json. When the same request is sent
+// multiple times, responses are replayed in the recorded order.
+type ReplayClient struct {
+	inner Gemini
+	dir   string
+	mode  ReplayMode
+
+	mu       sync.Mutex
+	fixtures map[string]*fixture
+	cursor   map[string]int
+}
+
+var _ Gemini = &ReplayClient{}
+
+// fixture is the file format of a recorded request and its responses
+type fixture struct {
+	Request   json.RawMessage   `json:"request"`
+	Responses []json.RawMessage `json:"responses"`
+}
+
+type generateContentRequest struct {
+	Contents []*genai.Content             `json:"contents"`
+	Config   *genai.GenerateContentConfig `json:"config,omitempty"`
+}
+
+type embeddingRequest struct {
+	Text       string `json:"text"`
+	Dimensions int    `json:"dimensions"`
+}
+
+// NewReplayClient creates a new record/replay decorator. inner is required
+// in record mode and ignored in replay mode.
+func NewReplayClient(inner Gemini, dir string, mode ReplayMode) (*ReplayClient, error) {
+	if dir == "" {
+		return nil, goerr.New("fixture directory is required")
+	}
+
+	switch mode {
+	case ReplayModeRecord:
+		if inner == nil {
+			return nil, goerr.New("inner client is required in record mode")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, goerr.Wrap(err, "failed to create fixture directory", goerr.V("dir", dir))
+		}
+	case ReplayModeReplay:
+	default:
+		return nil, goerr.New("invalid replay mode", goerr.V("mode", mode))
+	}
+
+	return &ReplayClient{
+		inner:    inner,
+		dir:      dir,
+		mode:     mode,
+		fixtures: make(map[string]*fixture),
+		cursor:   make(map[string]int),
+	}, nil
+}
+
+func (c *ReplayClient) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	req := &generateContentRequest{
+		Contents: contents,
+		Config:   normalizeConfig(config),
+	}
+
+	if c.mode == ReplayModeReplay {
+		var resp genai.GenerateContentResponse
+		if err := c.replay("generate", req, &resp); err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	}
+
+	resp, err := c.inner.GenerateContent(ctx, contents, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.record("generate", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GenerateContentStream is recorded and replayed as a non-streaming request,
+// so the whole response is yielded as a single chunk.
+func (c *ReplayClient) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		yield(c.GenerateContent(ctx, contents, config))
+	}
+}
+
+// CreateChat is passed through in record mode. Chat sessions hold their own
+// connection to the backend and can not be replayed.
+func (c *ReplayClient) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
+	if c.mode == ReplayModeReplay {
+		return nil, goerr.New("CreateChat is not supported in replay mode")
+	}
+	return c.inner.CreateChat(ctx, config, history)
+}
+
+func (c *ReplayClient) Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error) {
+	req := &embeddingRequest{
+		Text:       text,
+		Dimensions: dimensions,
+	}
+
+	if c.mode == ReplayModeReplay {
+		var resp firestore.Vector32
+		if err := c.replay("embedding", req, &resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	resp, err := c.inner.Embedding(ctx, text, dimensions)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.record("embedding", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *ReplayClient) record(kind string, req, resp any) error {
+	key, reqData, err := fixtureKey(kind, req)
+	if err != nil {
+		return err
+	}
+
+	respData, err := json.Marshal(resp)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal response for fixture", goerr.V("kind", kind))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.fixtures[key]
+	if !ok {
+		// Recording starts from scratch for each request so that stale responses are not mixed in
+		f = &fixture{Request: reqData}
+		c.fixtures[key] = f
+	}
+	f.Responses = append(f.Responses, respData)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal fixture", goerr.V("key", key))
+	}
+	if err := os.WriteFile(c.fixturePath(key), data, 0644); err != nil {
+		return goerr.Wrap(err, "failed to write fixture", goerr.V("key", key))
+	}
+
+	return nil
+}
+
+func (c *ReplayClient) replay(kind string, req, resp any) error {
+	key, _, err := fixtureKey(kind, req)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.fixtures[key]
+	if !ok {
+		data, err := os.ReadFile(c.fixturePath(key))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return goerr.Wrap(ErrFixtureNotFound, "no recorded response for request", goerr.V("kind", kind), goerr.V("key", key))
+			}
+			return goerr.Wrap(err, "failed to read fixture", goerr.V("key", key))
+		}
+
+		f = &fixture{}
+		if err := json.Unmarshal(data, f); err != nil {
+			return goerr.Wrap(err, "failed to parse fixture", goerr.V("key", key))
+		}
+		if len(f.Responses) == 0 {
+			return goerr.Wrap(ErrFixtureNotFound, "fixture has no responses", goerr.V("key", key))
+		}
+		c.fixtures[key] = f
+	}
+
+	// Serve responses in recorded order and repeat the last one when exhausted
+	idx := c.cursor[key]
+	if idx >= len(f.Responses) {
+		idx = len(f.Responses) - 1
+	}
+	c.cursor[key] = idx + 1
+
+	if err := json.Unmarshal(f.Responses[idx], resp); err != nil {
+		return goerr.Wrap(err, "failed to unmarshal recorded response", goerr.V("key", key))
+	}
+
+	return nil
+}
+
+func (c *ReplayClient) fixturePath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// fixtureKey returns the fixture key and canonical JSON of the request
+func fixtureKey(kind string, req any) (string, json.RawMessage, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", nil, goerr.Wrap(err, "failed to marshal request for fixture", goerr.V("kind", kind))
+	}
+
+	sum := sha256.Sum256(data)
+	return kind + "-" + hex.EncodeToString(sum[:]), data, nil
+}
+
+// normalizeConfig returns a copy of config with function declarations sorted by
+// name. tool.Registry builds declarations from a map, so their order is random.
+func normalizeConfig(config *genai.GenerateContentConfig) *genai.GenerateContentConfig {
+	if config == nil || len(config.Tools) == 0 {
+		return config
+	}
+
+	normalized := *config
+	normalized.Tools = make([]*genai.Tool, len(config.Tools))
+	for i, t := range config.Tools {
+		if t == nil || len(t.FunctionDeclarations) == 0 {
+			normalized.Tools[i] = t
+			continue
+		}
+
+		copied := *t
+		copied.FunctionDeclarations = make([]*genai.FunctionDeclaration, len(t.FunctionDeclarations))
+		copy(copied.FunctionDeclarations, t.FunctionDeclarations)
+		sort.Slice(copied.FunctionDeclarations, func(a, b int) bool {
+			return copied.FunctionDeclarations[a].Name < copied.FunctionDeclarations[b].Name
+		})
+		normalized.Tools[i] = &copied
+	}
+
+	return &normalized
+}
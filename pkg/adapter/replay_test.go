@@ -0,0 +1,101 @@
+package adapter_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"google.golang.org/genai"
+)
+
+// countingGemini returns a different response for each call
+type countingGemini struct {
+	calls int
+}
+
+func (m *countingGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	m.calls++
+	text := "first"
+	if m.calls > 1 {
+		text = "second"
+	}
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(text, genai.RoleModel)}},
+	}, nil
+}
+
+func (m *countingGemini) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		yield(m.GenerateContent(ctx, contents, config))
+	}
+}
+
+func (m *countingGemini) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *countingGemini) Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error) {
+	m.calls++
+	return firestore.Vector32{0.1, 0.2, 0.3}, nil
+}
+
+func TestReplayClient(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	contents := []*genai.Content{genai.NewContentFromText("hello", genai.RoleUser)}
+	configA := &genai.GenerateContentConfig{
+		Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "a"}, {Name: "b"}}}},
+	}
+	// Same tools in different order must be treated as the same request
+	configB := &genai.GenerateContentConfig{
+		Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "b"}, {Name: "a"}}}},
+	}
+
+	// Record
+	inner := &countingGemini{}
+	recorder, err := adapter.NewReplayClient(inner, dir, adapter.ReplayModeRecord)
+	gt.NoError(t, err)
+
+	resp, err := recorder.GenerateContent(ctx, contents, configA)
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Candidates[0].Content.Parts[0].Text, "first")
+
+	resp, err = recorder.GenerateContent(ctx, contents, configB)
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Candidates[0].Content.Parts[0].Text, "second")
+
+	_, err = recorder.Embedding(ctx, "hello", 3)
+	gt.NoError(t, err)
+	gt.Equal(t, inner.calls, 3)
+
+	// Replay without backend
+	replayer, err := adapter.NewReplayClient(nil, dir, adapter.ReplayModeReplay)
+	gt.NoError(t, err)
+
+	resp, err = replayer.GenerateContent(ctx, contents, configB)
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Candidates[0].Content.Parts[0].Text, "first")
+
+	resp, err = replayer.GenerateContent(ctx, contents, configA)
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Candidates[0].Content.Parts[0].Text, "second")
+
+	// Last response is repeated after recorded responses are exhausted
+	resp, err = replayer.GenerateContent(ctx, contents, configA)
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Candidates[0].Content.Parts[0].Text, "second")
+
+	vec, err := replayer.Embedding(ctx, "hello", 3)
+	gt.NoError(t, err)
+	gt.A(t, vec).Length(3)
+
+	// Unknown request
+	_, err = replayer.GenerateContent(ctx, []*genai.Content{genai.NewContentFromText("unknown", genai.RoleUser)}, nil)
+	gt.Error(t, err)
+	gt.True(t, errors.Is(err, adapter.ErrFixtureNotFound))
+}
@@ -16,23 +16,28 @@ type Storage interface {
 	Get(ctx context.Context, key string) (io.ReadCloser, error)
 }
 
-// storageClient implements Storage interface using Cloud Storage
-type storageClient struct {
-	bucketName string
-	prefix     string
-	client     *storage.Client
+// storageOptions holds settings shared by all Storage implementations
+type storageOptions struct {
+	prefix string
 }
 
 // StorageOption is a functional option for configuring Storage
-type StorageOption func(*storageClient)
+type StorageOption func(*storageOptions)
 
 // WithPrefix sets the prefix for object keys
 func WithPrefix(prefix string) StorageOption {
-	return func(s *storageClient) {
-		s.prefix = prefix
+	return func(o *storageOptions) {
+		o.prefix = prefix
 	}
 }
 
+// storageClient implements Storage interface using Cloud Storage
+type storageClient struct {
+	bucketName string
+	prefix     string
+	client     *storage.Client
+}
+
 // NewStorage creates a new Cloud Storage client
 func NewStorage(ctx context.Context, bucketName string, opts ...StorageOption) (Storage, error) {
 	client, err := storage.NewClient(ctx)
@@ -40,16 +45,16 @@ func NewStorage(ctx context.Context, bucketName string, opts ...StorageOption) (
 		return nil, goerr.Wrap(err, "failed to create storage client")
 	}
 
-	s := &storageClient{
-		bucketName: bucketName,
-		client:     client,
-	}
-
+	var o storageOptions
 	for _, opt := range opts {
-		opt(s)
+		opt(&o)
 	}
 
-	return s, nil
+	return &storageClient{
+		bucketName: bucketName,
+		prefix:     o.prefix,
+		client:     client,
+	}, nil
 }
 
 func (s *storageClient) Put(ctx context.Context, key string) (io.WriteCloser, error) {
@@ -0,0 +1,138 @@
+package adapter
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"google.golang.org/genai"
+)
+
+// Caller names used to group token usage
+const (
+	CallerSummary       = "summary"
+	CallerTitle         = "title"
+	CallerChat          = "chat"
+	CallerModeJudge     = "mode_judge"
+	CallerPlan          = "plan"
+	CallerStepExecution = "step_execution"
+	CallerReflection    = "reflection"
+	CallerConclusion    = "conclusion"
+	CallerCompress      = "compress"
+	CallerBigQueryAgent = "bigquery_agent"
+	CallerIntrospection = "introspection"
+	CallerEnrich        = "enrich"
+	CallerSuggest       = "suggest"
+	CallerOther         = "other"
+)
+
+type callerCtxKey struct{}
+
+// WithCaller returns a context labeled with caller name. UsageTracker groups
+// token usage by this label.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerCtxKey{}, caller)
+}
+
+// CallerFromContext returns caller name set by WithCaller, or CallerOther if not set
+func CallerFromContext(ctx context.Context) string {
+	if caller, ok := ctx.Value(callerCtxKey{}).(string); ok && caller != "" {
+		return caller
+	}
+	return CallerOther
+}
+
+// UsageTracker is a Gemini decorator that aggregates token usage reported in
+// UsageMetadata of each response, grouped by caller.
+type UsageTracker struct {
+	inner Gemini
+
+	mu    sync.Mutex
+	usage *model.TokenUsage
+}
+
+var _ Gemini = &UsageTracker{}
+
+// NewUsageTracker creates a new usage tracking decorator for inner
+func NewUsageTracker(inner Gemini) *UsageTracker {
+	return &UsageTracker{
+		inner: inner,
+		usage: &model.TokenUsage{ByCaller: make(map[string]*model.TokenCount)},
+	}
+}
+
+func (t *UsageTracker) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	resp, err := t.inner.GenerateContent(ctx, contents, config)
+	if err != nil {
+		return nil, err
+	}
+
+	t.add(CallerFromContext(ctx), resp.UsageMetadata)
+	return resp, nil
+}
+
+// GenerateContentStream records usage when the stream ends. UsageMetadata of
+// the last chunk holds the totals of the whole response.
+func (t *UsageTracker) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		var meta *genai.GenerateContentResponseUsageMetadata
+		received := false
+		defer func() {
+			if received {
+				t.add(CallerFromContext(ctx), meta)
+			}
+		}()
+
+		for resp, err := range t.inner.GenerateContentStream(ctx, contents, config) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			received = true
+			if resp.UsageMetadata != nil {
+				meta = resp.UsageMetadata
+			}
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
+
+// CreateChat is passed through. Usage of chat sessions is not tracked because
+// responses are returned by genai.Chat directly.
+func (t *UsageTracker) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
+	return t.inner.CreateChat(ctx, config, history)
+}
+
+func (t *UsageTracker) Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error) {
+	return t.inner.Embedding(ctx, text, dimensions)
+}
+
+// Usage returns a snapshot of aggregated token usage
+func (t *UsageTracker) Usage() *model.TokenUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := &model.TokenUsage{}
+	snapshot.Merge(t.usage)
+	return snapshot
+}
+
+func (t *UsageTracker) add(caller string, meta *genai.GenerateContentResponseUsageMetadata) {
+	count := &model.TokenCount{Calls: 1}
+	if meta != nil {
+		count.PromptTokens = int64(meta.PromptTokenCount)
+		count.ResponseTokens = int64(meta.CandidatesTokenCount)
+		count.ThinkingTokens = int64(meta.ThoughtsTokenCount)
+		count.TotalTokens = int64(meta.TotalTokenCount)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.usage.Merge(&model.TokenUsage{ByCaller: map[string]*model.TokenCount{caller: count}})
+}
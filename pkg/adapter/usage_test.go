@@ -0,0 +1,90 @@
+package adapter_test
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"google.golang.org/genai"
+)
+
+// usageGemini returns fixed usage metadata for each call
+type usageGemini struct {
+	countingGemini
+}
+
+func (m *usageGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: genai.NewContentFromText("ok", genai.RoleModel)}},
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     10,
+			CandidatesTokenCount: 5,
+			ThoughtsTokenCount:   3,
+			TotalTokenCount:      18,
+		},
+	}, nil
+}
+
+// GenerateContentStream returns two chunks and only the last one has final usage
+func (m *usageGemini) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		first := &genai.GenerateContentResponse{
+			Candidates:    []*genai.Candidate{{Content: genai.NewContentFromText("o", genai.RoleModel)}},
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, TotalTokenCount: 11},
+		}
+		if !yield(first, nil) {
+			return
+		}
+		yield(m.GenerateContent(ctx, contents, config))
+	}
+}
+
+func TestUsageTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker := adapter.NewUsageTracker(&usageGemini{})
+
+	contents := []*genai.Content{genai.NewContentFromText("hello", genai.RoleUser)}
+	_, err := tracker.GenerateContent(adapter.WithCaller(ctx, adapter.CallerPlan), contents, nil)
+	gt.NoError(t, err)
+	_, err = tracker.GenerateContent(adapter.WithCaller(ctx, adapter.CallerPlan), contents, nil)
+	gt.NoError(t, err)
+	_, err = tracker.GenerateContent(ctx, contents, nil)
+	gt.NoError(t, err)
+
+	usage := tracker.Usage()
+	gt.A(t, usage.Callers()).Length(2)
+
+	plan := usage.ByCaller[adapter.CallerPlan]
+	gt.Equal(t, plan.Calls, int64(2))
+	gt.Equal(t, plan.PromptTokens, int64(20))
+	gt.Equal(t, plan.ResponseTokens, int64(10))
+	gt.Equal(t, plan.ThinkingTokens, int64(6))
+	gt.Equal(t, plan.TotalTokens, int64(36))
+
+	gt.Equal(t, usage.ByCaller[adapter.CallerOther].Calls, int64(1))
+	gt.Equal(t, usage.Total().TotalTokens, int64(54))
+
+	// Snapshot must not be affected by later calls
+	_, err = tracker.GenerateContent(ctx, contents, nil)
+	gt.NoError(t, err)
+	gt.Equal(t, usage.ByCaller[adapter.CallerOther].Calls, int64(1))
+}
+
+func TestUsageTrackerStream(t *testing.T) {
+	ctx := adapter.WithCaller(context.Background(), adapter.CallerChat)
+	tracker := adapter.NewUsageTracker(&usageGemini{})
+
+	var chunks int
+	for resp, err := range tracker.GenerateContentStream(ctx, []*genai.Content{genai.NewContentFromText("hello", genai.RoleUser)}, nil) {
+		gt.NoError(t, err)
+		gt.V(t, resp).NotNil()
+		chunks++
+	}
+	gt.Equal(t, chunks, 2)
+
+	usage := tracker.Usage().ByCaller[adapter.CallerChat]
+	gt.Equal(t, usage.Calls, int64(1))
+	gt.Equal(t, usage.TotalTokens, int64(18))
+}
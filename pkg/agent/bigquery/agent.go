@@ -158,7 +158,7 @@ func (a *Agent) Execute(ctx context.Context, query string) (string, error) {
 	var finalResponse string
 
 	for i := 0; i < maxIterations; i++ {
-		resp, err := a.gemini.GenerateContent(ctx, contents, config)
+		resp, err := a.gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerBigQueryAgent), contents, config)
 		if err != nil {
 			return "", goerr.Wrap(err, "failed to generate content")
 		}
@@ -2,10 +2,13 @@ package bigquery
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/leveret/pkg/adapter"
+	"github.com/m-mizutani/leveret/pkg/model"
 	"google.golang.org/genai"
 )
 
@@ -310,3 +313,77 @@ func TestAgent_HandleRunbook(t *testing.T) {
 		gt.True(t, hasError)
 	})
 }
+
+// newTestGemini returns Gemini replaying fixtures in testdata/<test name>
+// without network. Set TEST_GEMINI_RECORD with TEST_GEMINI_PROJECT to record
+// the fixtures again with real Gemini.
+func newTestGemini(ctx context.Context, t *testing.T) adapter.Gemini {
+	dir := filepath.Join("testdata", t.Name())
+	if os.Getenv("TEST_GEMINI_RECORD") == "" {
+		gemini, err := adapter.NewReplayClient(nil, dir, adapter.ReplayModeReplay)
+		gt.NoError(t, err).Required()
+		return gemini
+	}
+
+	projectID := os.Getenv("TEST_GEMINI_PROJECT")
+	if projectID == "" {
+		t.Fatal("TEST_GEMINI_PROJECT is required to record fixtures")
+	}
+	inner, err := adapter.NewGemini(ctx, projectID, "us-central1")
+	gt.NoError(t, err).Required()
+
+	// Drop fixtures of the previous recording so that unused ones are not left
+	gt.NoError(t, os.RemoveAll(dir)).Required()
+	gemini, err := adapter.NewReplayClient(inner, dir, adapter.ReplayModeRecord)
+	gt.NoError(t, err).Required()
+	return gemini
+}
+
+func TestIntrospect(t *testing.T) {
+	ctx := context.Background()
+	gemini := newTestGemini(ctx, t)
+
+	queryText := "Find failed SSH logins from 198.51.100.7 in the last 24 hours"
+
+	// IDs are fixed to keep the prompt, and so the fixture key, stable
+	helpful := &model.Memory{
+		ID:    "5d0f3c8e-2b7a-4e91-8c6d-0a1b2c3d4e5f",
+		Claim: "SSH authentication logs are stored in `security-logs.auth.sshd`. Source IP is in `remote_addr` and the result is in `result` column",
+	}
+	harmful := &model.Memory{
+		ID:    "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b",
+		Claim: "SSH authentication logs are stored in `security-logs.auth.ssh_events`",
+	}
+	unused := &model.Memory{
+		ID:    "3a4b5c6d-7e8f-4a0b-9c1d-2e3f4a5b6c7d",
+		Claim: "Data access logs of Cloud Storage are stored in `audit.cloudaudit_googleapis_com_data_access`",
+	}
+
+	history := []*genai.Content{
+		genai.NewContentFromText(queryText, genai.RoleUser),
+		{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{
+			Name: "bigquery_query",
+			Args: map[string]any{"query": "SELECT timestamp, user FROM `security-logs.auth.ssh_events` WHERE source_ip = '198.51.100.7'"},
+		}}}},
+		{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			Name:     "bigquery_query",
+			Response: map[string]any{"error": "Not found: Table security-logs:auth.ssh_events was not found in location US"},
+		}}}},
+		{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{
+			Name: "bigquery_query",
+			Args: map[string]any{"query": "SELECT timestamp, user FROM `security-logs.auth.sshd` WHERE remote_addr = '198.51.100.7' AND result = 'failure' AND timestamp >= TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL 24 HOUR)"},
+		}}}},
+		{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			Name:     "bigquery_query",
+			Response: map[string]any{"job_id": "job-sshd-001", "total_rows": 12, "scan_size_mb": 42},
+		}}}},
+		genai.NewContentFromText("12 failed SSH logins from 198.51.100.7 were found in the last 24 hours, targeting root and admin.", genai.RoleModel),
+	}
+
+	result, err := introspect(ctx, gemini, queryText, []*model.Memory{helpful, harmful, unused}, history)
+	gt.NoError(t, err).Required()
+
+	gt.A(t, result.Claims).Longer(0)
+	gt.A(t, result.HelpfulMemoryIDs).Equal([]string{string(helpful.ID)})
+	gt.A(t, result.HarmfulMemoryIDs).Equal([]string{string(harmful.ID)})
+}
@@ -112,7 +112,7 @@ func introspect(ctx context.Context, gemini adapter.Gemini, queryText string, pr
 	}
 
 	// Generate introspection
-	resp, err := gemini.GenerateContent(ctx, introspectionContents, config)
+	resp, err := gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerIntrospection), introspectionContents, config)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to generate introspection")
 	}
@@ -10,13 +10,15 @@ import (
 	"github.com/briandowns/spinner"
 	"github.com/chzyer/readline"
 	"github.com/m-mizutani/goerr/v2"
-	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/adapter"
 	"github.com/m-mizutani/leveret/pkg/agent/bigquery"
+	"github.com/m-mizutani/leveret/pkg/model"
 	"github.com/m-mizutani/leveret/pkg/tool"
 	"github.com/m-mizutani/leveret/pkg/tool/alert"
 	"github.com/m-mizutani/leveret/pkg/tool/otx"
 	"github.com/m-mizutani/leveret/pkg/usecase/chat"
 	"github.com/urfave/cli/v3"
+	"google.golang.org/genai"
 )
 
 func chatCommand() *cli.Command {
@@ -24,12 +26,17 @@ func chatCommand() *cli.Command {
 		cfg             config
 		mcpCfg          mcpConfig
 		alertID         model.AlertID
+		incidentID      model.IncidentID
+		historyID       string
+		continueChat    bool
 		environmentInfo string
 	)
 
 	// Create tool registry early to get flags
 	registry := tool.New(
 		alert.NewSearchAlerts(),
+		alert.NewSearchByAttribute(),
+		alert.NewFindAlerts(),
 		otx.New(),
 		bigquery.New(),
 	)
@@ -41,7 +48,24 @@ func chatCommand() *cli.Command {
 			Usage:       "Alert ID to chat with",
 			Sources:     cli.EnvVars("LEVERET_ALERT_ID"),
 			Destination: (*string)(&alertID),
-			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "incident-id",
+			Usage:       "Incident ID to chat with, instead of an alert",
+			Sources:     cli.EnvVars("LEVERET_INCIDENT_ID"),
+			Destination: (*string)(&incidentID),
+		},
+		&cli.StringFlag{
+			Name:        "history-id",
+			Usage:       "History ID to resume an existing conversation",
+			Sources:     cli.EnvVars("LEVERET_HISTORY_ID"),
+			Destination: &historyID,
+		},
+		&cli.BoolFlag{
+			Name:        "continue",
+			Aliases:     []string{"c"},
+			Usage:       "Resume the latest conversation of the alert",
+			Destination: &continueChat,
 		},
 		&cli.StringFlag{
 			Name:        "environment-info",
@@ -57,19 +81,53 @@ func chatCommand() *cli.Command {
 
 	return &cli.Command{
 		Name:  "chat",
-		Usage: "Interactive analysis of an alert",
+		Usage: "Interactive analysis of an alert or an incident",
 		Flags: flags,
 		Action: func(ctx context.Context, c *cli.Command) error {
+			if (alertID == "") == (incidentID == "") {
+				return goerr.New("either --alert-id or --incident-id is required")
+			}
+			if historyID != "" && continueChat {
+				return goerr.New("--history-id and --continue can not be used together")
+			}
+
 			// Initialize dependencies
 			repo, err := cfg.newRepository()
 			if err != nil {
 				return err
 			}
 
-			gemini, err := cfg.newGemini(ctx)
+			// Resolve history to resume
+			var resumeID *model.HistoryID
+			if historyID != "" {
+				id := model.HistoryID(historyID)
+				resumeID = &id
+			} else if continueChat {
+				var histories []*model.History
+				target := "alert " + string(alertID)
+				if incidentID != "" {
+					histories, err = repo.ListHistoryByIncident(ctx, incidentID)
+					target = "incident " + string(incidentID)
+				} else {
+					histories, err = repo.ListHistoryByAlert(ctx, alertID)
+				}
+				if err != nil {
+					return goerr.Wrap(err, "failed to list histories")
+				}
+				if len(histories) == 0 {
+					fmt.Fprintf(c.Root().Writer, "No conversation history found for %s, starting a new conversation\n", target)
+				} else {
+					// Histories are returned in descending order of CreatedAt
+					resumeID = &histories[0].ID
+				}
+			}
+
+			llm, err := cfg.newGemini(ctx)
 			if err != nil {
 				return err
 			}
+			// Track token usage of all LLM calls including tools
+			gemini := adapter.NewUsageTracker(llm)
 
 			storage, err := cfg.newStorage(ctx)
 			if err != nil {
@@ -102,6 +160,19 @@ func chatCommand() *cli.Command {
 				fmt.Printf("No tools enabled\n")
 			}
 
+			// Render response text as it is streamed. The spinner is stopped on the first chunk.
+			var (
+				spin     *spinner.Spinner
+				streamed bool
+			)
+			onStream := func(text string) {
+				if !streamed {
+					spin.Stop()
+					streamed = true
+				}
+				fmt.Fprint(c.Root().Writer, text)
+			}
+
 			// Create chat session
 			session, err := chat.New(ctx, chat.NewInput{
 				Repo:            repo,
@@ -109,12 +180,21 @@ func chatCommand() *cli.Command {
 				Storage:         storage,
 				Registry:        registry,
 				AlertID:         alertID,
+				IncidentID:      incidentID,
+				HistoryID:       resumeID,
 				EnvironmentInfo: environmentInfo,
+				Usage:           gemini,
+				StreamHandler:   onStream,
+				Actor:           cfg.actor,
 			})
 			if err != nil {
 				return goerr.Wrap(err, "failed to create chat session")
 			}
 
+			if resumeID != nil {
+				printHistory(c.Root().Writer, session.History())
+			}
+
 			fmt.Printf("\n")
 			// Interactive chat loop with readline support
 			rl, err := readline.New("> ")
@@ -147,20 +227,23 @@ func chatCommand() *cli.Command {
 
 				// Start spinner with random words
 				words := []string{"analyzing", "processing", "thinking", "searching", "evaluating", "examining", "investigating", "reviewing"}
-				s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-				s.Suffix = " " + words[rand.Intn(len(words))] + "..."
-				s.Start()
+				spin = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+				spin.Suffix = " " + words[rand.Intn(len(words))] + "..."
+				spin.Start()
+				streamed = false
 
 				// Send message to Gemini
 				response, err := session.Send(ctx, message)
-				s.Stop()
+				spin.Stop()
 
 				if err != nil {
 					return goerr.Wrap(err, "failed to send message")
 				}
 
-				// Display response
-				if response != nil {
+				// Display response (text has been already rendered if streamed)
+				if streamed {
+					fmt.Fprintf(c.Root().Writer, "\n\n")
+				} else if response != nil {
 					for _, candidate := range response.Candidates {
 						if candidate.Content != nil {
 							for _, part := range candidate.Content.Parts {
@@ -174,8 +257,41 @@ func chatCommand() *cli.Command {
 				}
 			}
 
+			printUsage(c.Root().Writer, &cfg, gemini.Usage())
 			fmt.Fprintf(c.Root().Writer, "\nChat session completed\n")
 			return nil
 		},
 	}
 }
+
+// printHistory replays prior turns of a resumed conversation. Tool calls are
+// shown by name only and tool results are omitted to keep the output short.
+func printHistory(w io.Writer, history *model.History) {
+	fmt.Fprintf(w, "\nResuming conversation: %s (%s)\n", history.Title, history.ID)
+	fmt.Fprintf(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+
+	for _, content := range history.Contents {
+		if content == nil {
+			continue
+		}
+
+		for _, part := range content.Parts {
+			switch {
+			case part.Thought:
+				continue
+			case part.FunctionCall != nil:
+				fmt.Fprintf(w, "🔧 %s\n", part.FunctionCall.Name)
+			case part.FunctionResponse != nil:
+				continue
+			case part.Text == "":
+				continue
+			case content.Role == genai.RoleUser:
+				fmt.Fprintf(w, "\n> %s\n\n", part.Text)
+			default:
+				fmt.Fprintf(w, "%s\n", part.Text)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+}
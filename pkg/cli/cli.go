@@ -33,12 +33,23 @@ func Run(ctx context.Context, argv []string) *Error {
 			showCommand(),
 			searchCommand(),
 			similarCommand(),
+			pivotCommand(),
+			findCommand(),
+			ackCommand(),
+			assignCommand(),
 			resolveCommand(),
+			closeCommand(),
+			reopenCommand(),
 			mergeCommand(),
 			unmergeCommand(),
+			incidentCommand(),
+			timelineCommand(),
+			noteCommand(),
 			historyCommand(),
+			migrateCommand(),
+			serveCommand(),
 		},
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:        "log-level",
 				Aliases:     []string{"l"},
@@ -54,7 +65,7 @@ func Run(ctx context.Context, argv []string) *Error {
 				Sources:     cli.EnvVars("LEVERET_VERBOSE"),
 				Destination: &verbose,
 			},
-		},
+		}, formatFlags()...),
 		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
 			logger = logging.New(logLevel, os.Stderr)
 			logging.SetDefault(logger)
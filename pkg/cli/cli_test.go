@@ -0,0 +1,32 @@
+package cli_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/leveret/pkg/cli"
+)
+
+// TestHelp runs help of each command to catch flag definitions conflicting
+// with global flags, which panic when flags are parsed. --verbose of the root
+// command is also given because a command flag sharing its alias hides it.
+func TestHelp(t *testing.T) {
+	commands := [][]string{
+		{"new"}, {"chat"}, {"list"}, {"show"}, {"search"}, {"similar"}, {"pivot"}, {"find"},
+		{"ack"}, {"assign"}, {"resolve"}, {"close"}, {"reopen"}, {"merge"}, {"unmerge"},
+		{"incident", "create"}, {"incident", "add"}, {"incident", "remove"}, {"incident", "list"},
+		{"incident", "show"}, {"incident", "status"}, {"timeline"}, {"note"}, {"history"}, {"history", "export"},
+		{"migrate"},
+		{"serve"},
+	}
+
+	for _, args := range commands {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			argv := append([]string{"leveret"}, args...)
+			if err := cli.Run(context.Background(), append(argv, "--verbose", "--help")); err != nil {
+				t.Fatalf("failed to show help: %s", err.Message)
+			}
+		})
+	}
+}
@@ -2,34 +2,58 @@ package cli
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/leveret/pkg/adapter"
 	"github.com/m-mizutani/leveret/pkg/repository"
 	"github.com/m-mizutani/leveret/pkg/service/mcp"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
 	"github.com/urfave/cli/v3"
 )
 
 // config holds configuration values
 type config struct {
 	// Repository
+	repository       string
 	firestoreProject string
 	database         string
 
 	// Adapters
+	llmProvider           string
 	geminiProject         string
+	geminiAPIKey          string
 	geminiLocation        string
 	geminiGenerativeModel string
 	geminiEmbeddingModel  string
+	openaiBaseURL         string
+	openaiAPIKey          string
+	openaiGenerativeModel string
+	openaiEmbeddingModel  string
+
+	// Prices in USD per 1M tokens to estimate cost in usage report
+	inputTokenPrice  float64
+	outputTokenPrice float64
 
 	// Storage
 	bucketName    string
 	storagePrefix string
+
+	// Analyst name recorded in alert timeline
+	actor string
 }
 
 // globalFlags returns common flags used across commands with destination config
 func globalFlags(cfg *config) []cli.Flag {
 	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "repository",
+			Usage:       "Repository backend (firestore, local:/path/to/dir)",
+			Value:       "firestore",
+			Sources:     cli.EnvVars("LEVERET_REPOSITORY"),
+			Destination: &cfg.repository,
+		},
 		&cli.StringFlag{
 			Name:        "firestore-project",
 			Aliases:     []string{"p"},
@@ -47,7 +71,7 @@ func globalFlags(cfg *config) []cli.Flag {
 		&cli.StringFlag{
 			Name:        "storage-bucket",
 			Aliases:     []string{"b"},
-			Usage:       "Cloud Storage bucket name",
+			Usage:       "Cloud Storage bucket name, or file:///path/to/dir for local directory",
 			Sources:     cli.EnvVars("LEVERET_STORAGE_BUCKET"),
 			Destination: &cfg.bucketName,
 		},
@@ -57,18 +81,37 @@ func globalFlags(cfg *config) []cli.Flag {
 			Sources:     cli.EnvVars("LEVERET_STORAGE_PREFIX"),
 			Destination: &cfg.storagePrefix,
 		},
+		&cli.StringFlag{
+			Name:        "actor",
+			Usage:       "Analyst name recorded in alert timeline",
+			Sources:     cli.EnvVars("LEVERET_ACTOR", "USER"),
+			Destination: &cfg.actor,
+		},
 	}
 }
 
 // llmFlags returns flags for LLM-related configuration with destination config
 func llmFlags(cfg *config) []cli.Flag {
 	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "llm-provider",
+			Usage:       "LLM provider (gemini, openai)",
+			Value:       "gemini",
+			Sources:     cli.EnvVars("LEVERET_LLM_PROVIDER"),
+			Destination: &cfg.llmProvider,
+		},
 		&cli.StringFlag{
 			Name:        "gemini-project",
 			Usage:       "Google Cloud project ID for Gemini API",
 			Sources:     cli.EnvVars("LEVERET_GEMINI_PROJECT"),
 			Destination: &cfg.geminiProject,
 		},
+		&cli.StringFlag{
+			Name:        "gemini-api-key",
+			Usage:       "Gemini API key (Google AI Studio). If set, Gemini API is used instead of Vertex AI",
+			Sources:     cli.EnvVars("LEVERET_GEMINI_API_KEY"),
+			Destination: &cfg.geminiAPIKey,
+		},
 		&cli.StringFlag{
 			Name:        "gemini-location",
 			Usage:       "Google Cloud location for Gemini API",
@@ -90,11 +133,61 @@ func llmFlags(cfg *config) []cli.Flag {
 			Sources:     cli.EnvVars("LEVERET_GEMINI_EMBEDDING_MODEL"),
 			Destination: &cfg.geminiEmbeddingModel,
 		},
+		&cli.StringFlag{
+			Name:        "openai-base-url",
+			Usage:       "Base URL of OpenAI-compatible API (e.g. http://localhost:11434/v1 for Ollama)",
+			Value:       "https://api.openai.com/v1",
+			Sources:     cli.EnvVars("LEVERET_OPENAI_BASE_URL"),
+			Destination: &cfg.openaiBaseURL,
+		},
+		&cli.StringFlag{
+			Name:        "openai-api-key",
+			Usage:       "API key for OpenAI-compatible API",
+			Sources:     cli.EnvVars("LEVERET_OPENAI_API_KEY", "OPENAI_API_KEY"),
+			Destination: &cfg.openaiAPIKey,
+		},
+		&cli.StringFlag{
+			Name:        "openai-generative-model",
+			Usage:       "Generative model name for OpenAI-compatible API",
+			Value:       "gpt-4o-mini",
+			Sources:     cli.EnvVars("LEVERET_OPENAI_GENERATIVE_MODEL"),
+			Destination: &cfg.openaiGenerativeModel,
+		},
+		&cli.StringFlag{
+			Name:        "openai-embedding-model",
+			Usage:       "Embedding model name for OpenAI-compatible API",
+			Value:       "text-embedding-3-small",
+			Sources:     cli.EnvVars("LEVERET_OPENAI_EMBEDDING_MODEL"),
+			Destination: &cfg.openaiEmbeddingModel,
+		},
+		&cli.FloatFlag{
+			Name:        "input-token-price",
+			Usage:       "Price in USD per 1M input tokens to estimate cost in usage report",
+			Sources:     cli.EnvVars("LEVERET_INPUT_TOKEN_PRICE"),
+			Destination: &cfg.inputTokenPrice,
+		},
+		&cli.FloatFlag{
+			Name:        "output-token-price",
+			Usage:       "Price in USD per 1M output (response and thinking) tokens to estimate cost in usage report",
+			Sources:     cli.EnvVars("LEVERET_OUTPUT_TOKEN_PRICE"),
+			Destination: &cfg.outputTokenPrice,
+		},
 	}
 }
 
 // newRepository creates a new repository instance
 func (cfg *config) newRepository() (repository.Repository, error) {
+	if dir, ok := strings.CutPrefix(cfg.repository, "local:"); ok {
+		repo, err := repository.NewLocal(dir)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create local repository")
+		}
+		return repo, nil
+	}
+	if cfg.repository != "" && cfg.repository != "firestore" {
+		return nil, goerr.New("unsupported repository", goerr.V("repository", cfg.repository))
+	}
+
 	if cfg.firestoreProject == "" {
 		return nil, goerr.New("firestore-project is required")
 	}
@@ -109,13 +202,25 @@ func (cfg *config) newRepository() (repository.Repository, error) {
 	return repo, nil
 }
 
-// newGemini creates a new Gemini adapter instance
+// newGemini creates a new LLM adapter instance for the selected provider
 func (cfg *config) newGemini(ctx context.Context) (adapter.Gemini, error) {
-	if cfg.geminiProject == "" {
-		return nil, goerr.New("gemini-project is required")
+	switch cfg.llmProvider {
+	case "", "gemini":
+		// Use Gemini (default)
+	case "openai":
+		return cfg.newOpenAI()
+	default:
+		return nil, goerr.New("unsupported LLM provider", goerr.V("provider", cfg.llmProvider))
+	}
+
+	if cfg.geminiProject == "" && cfg.geminiAPIKey == "" {
+		return nil, goerr.New("gemini-project or gemini-api-key is required")
 	}
 
 	var opts []adapter.GeminiOption
+	if cfg.geminiAPIKey != "" {
+		opts = append(opts, adapter.WithAPIKey(cfg.geminiAPIKey))
+	}
 	if cfg.geminiGenerativeModel != "" {
 		opts = append(opts, adapter.WithGenerativeModel(cfg.geminiGenerativeModel))
 	}
@@ -126,6 +231,25 @@ func (cfg *config) newGemini(ctx context.Context) (adapter.Gemini, error) {
 	return adapter.NewGemini(ctx, cfg.geminiProject, cfg.geminiLocation, opts...)
 }
 
+// newOpenAI creates a new OpenAI-compatible adapter instance
+func (cfg *config) newOpenAI() (adapter.Gemini, error) {
+	if cfg.openaiBaseURL == "" {
+		return nil, goerr.New("openai-base-url is required")
+	}
+
+	opts := []adapter.OpenAIOption{
+		adapter.WithOpenAIAPIKey(cfg.openaiAPIKey),
+	}
+	if cfg.openaiGenerativeModel != "" {
+		opts = append(opts, adapter.WithOpenAIGenerativeModel(cfg.openaiGenerativeModel))
+	}
+	if cfg.openaiEmbeddingModel != "" {
+		opts = append(opts, adapter.WithOpenAIEmbeddingModel(cfg.openaiEmbeddingModel))
+	}
+
+	return adapter.NewOpenAI(cfg.openaiBaseURL, opts...)
+}
+
 // newStorage creates a new Storage adapter instance
 func (cfg *config) newStorage(ctx context.Context) (adapter.Storage, error) {
 	if cfg.bucketName == "" {
@@ -137,6 +261,14 @@ func (cfg *config) newStorage(ctx context.Context) (adapter.Storage, error) {
 		opts = append(opts, adapter.WithPrefix(cfg.storagePrefix))
 	}
 
+	if dir, ok := strings.CutPrefix(cfg.bucketName, "file://"); ok {
+		storage, err := adapter.NewFileStorage(dir, opts...)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create file storage")
+		}
+		return storage, nil
+	}
+
 	storage, err := adapter.NewStorage(ctx, cfg.bucketName, opts...)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to create storage")
@@ -165,3 +297,48 @@ func mcpFlags(cfg *mcpConfig) []cli.Flag {
 func (cfg *mcpConfig) newMCP(ctx context.Context) (*mcp.Provider, error) {
 	return mcp.LoadAndConnect(ctx, cfg.configPath)
 }
+
+// groupConfig holds configuration of automatic alert grouping on ingestion
+type groupConfig struct {
+	action    string
+	threshold float64
+	window    time.Duration
+}
+
+// groupFlags returns flags for alert grouping with destination config
+func groupFlags(cfg *groupConfig) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "group-action",
+			Usage:       "How to group a new alert into a matched open alert (merge, incident)",
+			Value:       string(alert.GroupActionMerge),
+			Sources:     cli.EnvVars("LEVERET_GROUP_ACTION"),
+			Destination: &cfg.action,
+			Validator: func(v string) error {
+				return alert.GroupAction(v).Validate()
+			},
+		},
+		&cli.FloatFlag{
+			Name:        "group-threshold",
+			Usage:       "Max cosine distance to group a new alert into a similar open alert (0 to disable)",
+			Sources:     cli.EnvVars("LEVERET_GROUP_THRESHOLD"),
+			Destination: &cfg.threshold,
+		},
+		&cli.DurationFlag{
+			Name:        "group-window",
+			Usage:       "Only group into alerts created within this duration (0 for no limit)",
+			Value:       24 * time.Hour,
+			Sources:     cli.EnvVars("LEVERET_GROUP_WINDOW"),
+			Destination: &cfg.window,
+		},
+	}
+}
+
+// option returns alert usecase option for grouping
+func (cfg *groupConfig) option() alert.Option {
+	return alert.WithGrouping(alert.GroupConfig{
+		Action:    alert.GroupAction(cfg.action),
+		Threshold: cfg.threshold,
+		Window:    cfg.window,
+	})
+}
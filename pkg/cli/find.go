@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+)
+
+func findCommand() *cli.Command {
+	var (
+		cfg       config
+		limit     int64
+		threshold float64
+	)
+
+	flags := []cli.Flag{
+		&cli.IntFlag{
+			Name:        "limit",
+			Usage:       "Maximum number of alerts to display",
+			Value:       10,
+			Sources:     cli.EnvVars("LEVERET_FIND_LIMIT"),
+			Destination: &limit,
+		},
+		&cli.FloatFlag{
+			Name:        "threshold",
+			Aliases:     []string{"t"},
+			Usage:       "Cosine distance threshold of semantic matching (0.0-2.0, lower is more similar)",
+			Value:       1.0,
+			Sources:     cli.EnvVars("LEVERET_FIND_THRESHOLD"),
+			Destination: &threshold,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+	flags = append(flags, llmFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:      "find",
+		Usage:     "Find alerts by natural language with semantic, keyword and attribute matching",
+		ArgsUsage: "<query>",
+		Flags:     flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			query := strings.Join(c.Args().Slice(), " ")
+			if strings.TrimSpace(query) == "" {
+				return goerr.New("query is required, e.g. leveret find \"login from 192.0.2.1\"")
+			}
+
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			gemini, err := cfg.newGemini(ctx)
+			if err != nil {
+				return err
+			}
+
+			out := newPrinter(c)
+
+			var progress io.Writer = c.Root().Writer
+			if out.structured() {
+				progress = io.Discard
+			}
+			uc := alert.New(repo, gemini, alert.WithOutput(progress))
+
+			results, err := uc.Find(ctx, alert.FindOptions{
+				Query:     query,
+				Limit:     int(limit),
+				Threshold: threshold,
+			})
+			if err != nil {
+				return goerr.Wrap(err, "failed to find alerts")
+			}
+
+			if out.structured() {
+				return out.FindResults(results)
+			}
+
+			if len(results) == 0 {
+				fmt.Fprintf(c.Root().Writer, "No alerts found\n")
+				return nil
+			}
+
+			fmt.Fprintf(c.Root().Writer, "Found %d alerts:\n\n", len(results))
+			for i, r := range results {
+				distance := "-"
+				if r.Distance != nil {
+					distance = fmt.Sprintf("%.4f", *r.Distance)
+				}
+				fmt.Fprintf(c.Root().Writer, "%d. %s (%s)\n", i+1, r.Alert.ID, r.Alert.Title)
+				fmt.Fprintf(c.Root().Writer, "   Score: %.4f  Distance: %s  Matched by: %s\n",
+					r.Score, distance, strings.Join(r.MatchedBy, ", "))
+				fmt.Fprintf(c.Root().Writer, "\n")
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,386 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	formatTable outputFormat = "table"
+	formatJSON  outputFormat = "json"
+	formatJSONL outputFormat = "jsonl"
+	formatYAML  outputFormat = "yaml"
+)
+
+// formatFlags returns flags for output format. They are defined on the root
+// command and inherited by all subcommands.
+func formatFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Usage:   "Output format of read commands (table, json, jsonl, yaml)",
+			Value:   string(formatTable),
+			Sources: cli.EnvVars("LEVERET_FORMAT"),
+			Validator: func(v string) error {
+				switch outputFormat(v) {
+				case formatTable, formatJSON, formatJSONL, formatYAML:
+					return nil
+				default:
+					return goerr.New("invalid output format", goerr.V("format", v))
+				}
+			},
+		},
+		&cli.BoolFlag{
+			Name:    "with-embedding",
+			Usage:   "Include embedding vector of alerts in structured output",
+			Sources: cli.EnvVars("LEVERET_WITH_EMBEDDING"),
+		},
+	}
+}
+
+// printer writes command results in the format specified by --format
+type printer struct {
+	w             io.Writer
+	format        outputFormat
+	withEmbedding bool
+}
+
+func newPrinter(c *cli.Command) *printer {
+	format := outputFormat(c.String("format"))
+	if format == "" {
+		format = formatTable
+	}
+
+	return &printer{
+		w:             c.Root().Writer,
+		format:        format,
+		withEmbedding: c.Bool("with-embedding"),
+	}
+}
+
+// printNextPageToken writes a token of the next page to stderr so that
+// stdout keeps only the results
+func printNextPageToken(c *cli.Command, token string) {
+	if token == "" {
+		return
+	}
+	fmt.Fprintf(c.Root().ErrWriter, "Next page: --page-token %s\n", token)
+}
+
+// structured returns true if output should be machine readable instead of
+// human readable table
+func (p *printer) structured() bool {
+	return p.format != formatTable
+}
+
+// alertView is the stable output schema of model.Alert
+type alertView struct {
+	ID          model.AlertID      `json:"id" yaml:"id"`
+	Title       string             `json:"title" yaml:"title"`
+	Description string             `json:"description" yaml:"description"`
+	Data        any                `json:"data" yaml:"data"`
+	Attributes  []*model.Attribute `json:"attributes" yaml:"attributes"`
+	Embedding   []float32          `json:"embedding,omitempty" yaml:"embedding,omitempty"`
+	Status      model.AlertStatus  `json:"status" yaml:"status"`
+	Assignee    string             `json:"assignee" yaml:"assignee"`
+	Severity    model.Severity     `json:"severity" yaml:"severity"`
+	Triage      *triageView        `json:"triage,omitempty" yaml:"triage,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" yaml:"created_at"`
+	ResolvedAt  *time.Time         `json:"resolved_at" yaml:"resolved_at"`
+	Conclusion  model.Conclusion   `json:"conclusion" yaml:"conclusion"`
+	Note        string             `json:"note" yaml:"note"`
+	MergedTo    model.AlertID      `json:"merged_to" yaml:"merged_to"`
+	MergeTree   *mergeTreeView     `json:"merge_tree,omitempty" yaml:"merge_tree,omitempty"`
+}
+
+// mergeTreeView is the output schema of a merge tree of alerts
+type mergeTreeView struct {
+	ID     model.AlertID     `json:"id" yaml:"id"`
+	Title  string            `json:"title" yaml:"title"`
+	Status model.AlertStatus `json:"status" yaml:"status"`
+	Merged []*mergeTreeView  `json:"merged" yaml:"merged"`
+}
+
+func newMergeTreeView(node *alert.MergeNode) *mergeTreeView {
+	v := &mergeTreeView{
+		ID:     node.Alert.ID,
+		Title:  node.Alert.Title,
+		Status: node.Alert.CurrentStatus(),
+		Merged: make([]*mergeTreeView, len(node.Merged)),
+	}
+	for i, child := range node.Merged {
+		v.Merged[i] = newMergeTreeView(child)
+	}
+	return v
+}
+
+// triageView is the output schema of workflow results of an alert
+type triageView struct {
+	Action      model.TriageAction  `json:"action" yaml:"action"`
+	Note        string              `json:"note" yaml:"note"`
+	Enrichments []*model.Enrichment `json:"enrichments" yaml:"enrichments"`
+}
+
+func (p *printer) newAlertView(a *model.Alert) *alertView {
+	v := &alertView{
+		ID:          a.ID,
+		Title:       a.Title,
+		Description: a.Description,
+		Data:        a.Data,
+		Attributes:  a.Attributes,
+		CreatedAt:   a.CreatedAt,
+		ResolvedAt:  a.ResolvedAt,
+		Conclusion:  a.Conclusion,
+		Note:        a.Note,
+		MergedTo:    a.MergedTo,
+		Status:      a.CurrentStatus(),
+		Assignee:    a.Assignee,
+		Severity:    a.Severity,
+	}
+	if v.Attributes == nil {
+		v.Attributes = []*model.Attribute{}
+	}
+	if a.TriageAction != "" || len(a.Enrichments) > 0 {
+		v.Triage = &triageView{
+			Action:      a.TriageAction,
+			Note:        a.TriageNote,
+			Enrichments: a.Enrichments,
+		}
+		if v.Triage.Enrichments == nil {
+			v.Triage.Enrichments = []*model.Enrichment{}
+		}
+	}
+	if p.withEmbedding {
+		v.Embedding = a.Embedding
+	}
+	return v
+}
+
+// historyView is the stable output schema of model.History
+type historyView struct {
+	ID         model.HistoryID   `json:"id" yaml:"id"`
+	Title      string            `json:"title" yaml:"title"`
+	AlertID    model.AlertID     `json:"alert_id" yaml:"alert_id"`
+	IncidentID model.IncidentID  `json:"incident_id,omitempty" yaml:"incident_id,omitempty"`
+	CreatedAt  time.Time         `json:"created_at" yaml:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at" yaml:"updated_at"`
+	Usage      *model.TokenUsage `json:"usage,omitempty" yaml:"usage,omitempty"`
+}
+
+// activityView is the stable output schema of model.Activity
+type activityView struct {
+	ID         model.ActivityID   `json:"id" yaml:"id"`
+	AlertID    model.AlertID      `json:"alert_id" yaml:"alert_id"`
+	IncidentID model.IncidentID   `json:"incident_id,omitempty" yaml:"incident_id,omitempty"`
+	Type       model.ActivityType `json:"type" yaml:"type"`
+	Actor      string             `json:"actor" yaml:"actor"`
+	CreatedAt  time.Time          `json:"created_at" yaml:"created_at"`
+	From       string             `json:"from,omitempty" yaml:"from,omitempty"`
+	To         string             `json:"to,omitempty" yaml:"to,omitempty"`
+	Note       string             `json:"note,omitempty" yaml:"note,omitempty"`
+	RelatedID  string             `json:"related_id,omitempty" yaml:"related_id,omitempty"`
+}
+
+func newActivityView(a *model.Activity) *activityView {
+	return &activityView{
+		ID:         a.ID,
+		AlertID:    a.AlertID,
+		IncidentID: a.IncidentID,
+		Type:       a.Type,
+		Actor:      a.Actor,
+		CreatedAt:  a.CreatedAt,
+		From:       a.From,
+		To:         a.To,
+		Note:       a.Note,
+		RelatedID:  a.RelatedID,
+	}
+}
+
+// incidentView is the stable output schema of model.Incident
+type incidentView struct {
+	ID          model.IncidentID  `json:"id" yaml:"id"`
+	Title       string            `json:"title" yaml:"title"`
+	Description string            `json:"description" yaml:"description"`
+	Status      model.AlertStatus `json:"status" yaml:"status"`
+	Assignee    string            `json:"assignee" yaml:"assignee"`
+	AlertIDs    []model.AlertID   `json:"alert_ids" yaml:"alert_ids"`
+	CreatedAt   time.Time         `json:"created_at" yaml:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" yaml:"updated_at"`
+}
+
+func newIncidentView(x *model.Incident) *incidentView {
+	v := &incidentView{
+		ID:          x.ID,
+		Title:       x.Title,
+		Description: x.Description,
+		Status:      x.CurrentStatus(),
+		Assignee:    x.Assignee,
+		AlertIDs:    x.AlertIDs,
+		CreatedAt:   x.CreatedAt,
+		UpdatedAt:   x.UpdatedAt,
+	}
+	if v.AlertIDs == nil {
+		v.AlertIDs = []model.AlertID{}
+	}
+	return v
+}
+
+// incidentDetailView is the output schema of an incident with its member
+// alerts and timeline
+type incidentDetailView struct {
+	incidentView `yaml:",inline"`
+	Alerts       []*alertView    `json:"alerts" yaml:"alerts"`
+	Timeline     []*activityView `json:"timeline" yaml:"timeline"`
+}
+
+// findResultView is the output schema of a result of hybrid search
+type findResultView struct {
+	Score     float64    `json:"score" yaml:"score"`
+	Distance  *float64   `json:"distance" yaml:"distance"`
+	MatchedBy []string   `json:"matched_by" yaml:"matched_by"`
+	Alert     *alertView `json:"alert" yaml:"alert"`
+}
+
+func newHistoryView(h *model.History) *historyView {
+	return &historyView{
+		ID:         h.ID,
+		Title:      h.Title,
+		AlertID:    h.AlertID,
+		IncidentID: h.IncidentID,
+		CreatedAt:  h.CreatedAt,
+		UpdatedAt:  h.UpdatedAt,
+		Usage:      h.Usage,
+	}
+}
+
+// Alert writes a single alert in structured format. The merge tree is
+// omitted if tree is nil.
+func (p *printer) Alert(a *model.Alert, tree *alert.MergeNode) error {
+	v := p.newAlertView(a)
+	if tree != nil {
+		v.MergeTree = newMergeTreeView(tree)
+	}
+	return p.write(v)
+}
+
+// Alerts writes alerts in structured format. json and yaml emit a list and
+// jsonl emits one alert per line.
+func (p *printer) Alerts(alerts []*model.Alert) error {
+	views := make([]*alertView, len(alerts))
+	for i, a := range alerts {
+		views[i] = p.newAlertView(a)
+	}
+	return writeList(p, views)
+}
+
+// Activities writes activities in structured format
+func (p *printer) Activities(activities []*model.Activity) error {
+	views := make([]*activityView, len(activities))
+	for i, a := range activities {
+		views[i] = newActivityView(a)
+	}
+	return writeList(p, views)
+}
+
+// FindResults writes results of hybrid search in structured format
+func (p *printer) FindResults(results []*alert.FindResult) error {
+	views := make([]*findResultView, len(results))
+	for i, r := range results {
+		views[i] = &findResultView{
+			Score:     r.Score,
+			Distance:  r.Distance,
+			MatchedBy: r.MatchedBy,
+			Alert:     p.newAlertView(r.Alert),
+		}
+	}
+	return writeList(p, views)
+}
+
+// Incidents writes incidents in structured format
+func (p *printer) Incidents(incidents []*model.Incident) error {
+	views := make([]*incidentView, len(incidents))
+	for i, x := range incidents {
+		views[i] = newIncidentView(x)
+	}
+	return writeList(p, views)
+}
+
+// Incident writes an incident with its member alerts and timeline in
+// structured format
+func (p *printer) Incident(x *model.Incident, alerts []*model.Alert, activities []*model.Activity) error {
+	v := &incidentDetailView{
+		incidentView: *newIncidentView(x),
+		Alerts:       make([]*alertView, len(alerts)),
+		Timeline:     make([]*activityView, len(activities)),
+	}
+	for i, a := range alerts {
+		v.Alerts[i] = p.newAlertView(a)
+	}
+	for i, a := range activities {
+		v.Timeline[i] = newActivityView(a)
+	}
+	return p.write(v)
+}
+
+// Histories writes histories in structured format
+func (p *printer) Histories(histories []*model.History) error {
+	views := make([]*historyView, len(histories))
+	for i, h := range histories {
+		views[i] = newHistoryView(h)
+	}
+	return writeList(p, views)
+}
+
+func writeList[T any](p *printer, items []T) error {
+	if p.format == formatJSONL {
+		for _, item := range items {
+			if err := p.write(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return p.write(items)
+}
+
+func (p *printer) write(v any) error {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return goerr.Wrap(err, "failed to encode JSON output")
+		}
+
+	case formatJSONL:
+		if err := json.NewEncoder(p.w).Encode(v); err != nil {
+			return goerr.Wrap(err, "failed to encode JSONL output")
+		}
+
+	case formatYAML:
+		enc := yaml.NewEncoder(p.w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return goerr.Wrap(err, "failed to encode YAML output")
+		}
+		if err := enc.Close(); err != nil {
+			return goerr.Wrap(err, "failed to flush YAML output")
+		}
+
+	default:
+		return goerr.New("unsupported structured output format", goerr.V("format", p.format))
+	}
+
+	return nil
+}
@@ -3,16 +3,21 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/chat"
 	"github.com/urfave/cli/v3"
 )
 
 func historyCommand() *cli.Command {
 	var (
-		cfg     config
-		alertID string
+		cfg       config
+		alertID   string
+		pageToken string
+		limit     int64
 	)
 
 	flags := []cli.Flag{
@@ -22,15 +27,30 @@ func historyCommand() *cli.Command {
 			Usage:       "Alert ID to list conversation histories",
 			Sources:     cli.EnvVars("LEVERET_ALERT_ID"),
 			Destination: &alertID,
-			Required:    true,
+		},
+		&cli.IntFlag{
+			Name:        "limit",
+			Usage:       "Maximum number of histories to list when alert-id is not given",
+			Value:       100,
+			Sources:     cli.EnvVars("LEVERET_HISTORY_LIMIT"),
+			Destination: &limit,
+		},
+		&cli.StringFlag{
+			Name:        "page-token",
+			Usage:       "Token to get the next page, printed by the previous history list",
+			Sources:     cli.EnvVars("LEVERET_HISTORY_PAGE_TOKEN"),
+			Destination: &pageToken,
 		},
 	}
 	flags = append(flags, globalFlags(&cfg)...)
 
 	return &cli.Command{
 		Name:  "history",
-		Usage: "List conversation histories for an alert",
+		Usage: "List conversation histories of an alert, or all histories if alert-id is not given",
 		Flags: flags,
+		Commands: []*cli.Command{
+			historyExportCommand(),
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Initialize repository
 			repo, err := cfg.newRepository()
@@ -38,14 +58,34 @@ func historyCommand() *cli.Command {
 				return err
 			}
 
-			// List histories
-			histories, err := repo.ListHistoryByAlert(ctx, model.AlertID(alertID))
+			// List histories. alert-id is not marked as required because it
+			// would be also required by subcommands.
+			var histories []*model.History
+			if alertID != "" {
+				histories, err = repo.ListHistoryByAlert(ctx, model.AlertID(alertID))
+			} else {
+				var nextPageToken string
+				histories, nextPageToken, err = repo.ListHistory(ctx, &repository.ListHistoryInput{
+					Limit:     int(limit),
+					PageToken: pageToken,
+				})
+				defer printNextPageToken(c, nextPageToken)
+			}
 			if err != nil {
 				return goerr.Wrap(err, "failed to list histories")
 			}
 
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Histories(histories)
+			}
+
 			// Display histories
 			if len(histories) == 0 {
+				if alertID == "" {
+					fmt.Fprintf(c.Root().Writer, "No conversation histories found\n")
+					return nil
+				}
 				fmt.Fprintf(c.Root().Writer, "No conversation histories found for alert %s\n", alertID)
 				return nil
 			}
@@ -63,3 +103,78 @@ func historyCommand() *cli.Command {
 		},
 	}
 }
+
+func historyExportCommand() *cli.Command {
+	var (
+		cfg        config
+		historyID  string
+		format     string
+		outputPath string
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "history-id",
+			Usage:       "History ID to export",
+			Sources:     cli.EnvVars("LEVERET_HISTORY_ID"),
+			Destination: &historyID,
+			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "report-format",
+			Usage:       "Report format (md, html)",
+			Value:       string(chat.ExportFormatMarkdown),
+			Destination: &format,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Output file path (default: stdout)",
+			Destination: &outputPath,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export a conversation history as an investigation report",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			exportFormat := chat.ExportFormat(format)
+			if err := exportFormat.Validate(); err != nil {
+				return err
+			}
+
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			storage, err := cfg.newStorage(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := c.Root().Writer
+			if outputPath != "" {
+				f, err := os.Create(outputPath)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create output file", goerr.V("path", outputPath))
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if err := chat.Export(ctx, w, chat.ExportInput{
+				Repo:      repo,
+				Storage:   storage,
+				HistoryID: model.HistoryID(historyID),
+				Format:    exportFormat,
+			}); err != nil {
+				return goerr.Wrap(err, "failed to export history")
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,354 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+)
+
+func incidentCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "incident",
+		Usage: "Manage incidents that group multiple alerts",
+		Commands: []*cli.Command{
+			incidentCreateCommand(),
+			incidentAddCommand(),
+			incidentRemoveCommand(),
+			incidentListCommand(),
+			incidentShowCommand(),
+			incidentStatusCommand(),
+		},
+	}
+}
+
+func incidentIDFlag(usage string, incidentID *model.IncidentID) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "incident-id",
+		Usage:       usage,
+		Sources:     cli.EnvVars("LEVERET_INCIDENT_ID"),
+		Destination: (*string)(incidentID),
+		Required:    true,
+	}
+}
+
+func alertIDsFlag(usage string, alertIDs *[]string, required bool) cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:        "alert-id",
+		Aliases:     []string{"i"},
+		Usage:       usage,
+		Sources:     cli.EnvVars("LEVERET_ALERT_ID"),
+		Destination: alertIDs,
+		Required:    required,
+	}
+}
+
+func toAlertIDs(values []string) []model.AlertID {
+	ids := make([]model.AlertID, len(values))
+	for i, v := range values {
+		ids[i] = model.AlertID(v)
+	}
+	return ids
+}
+
+func incidentCreateCommand() *cli.Command {
+	var (
+		cfg         config
+		title       string
+		description string
+		alertIDs    []string
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "title",
+			Aliases:     []string{"t"},
+			Usage:       "Title of the incident",
+			Destination: &title,
+			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "description",
+			Usage:       "Description of the incident",
+			Destination: &description,
+		},
+		alertIDsFlag("Alert ID to add to the incident. Can be repeated", &alertIDs, false),
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "create",
+		Usage: "Create a new incident",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			incident, err := uc.CreateIncident(ctx, title, description, toAlertIDs(alertIDs))
+			if err != nil {
+				return goerr.Wrap(err, "failed to create incident")
+			}
+
+			fmt.Fprintf(c.Root().Writer, "Incident created: %s (%d alerts)\n", incident.ID, len(incident.AlertIDs))
+			return nil
+		},
+	}
+}
+
+func incidentAddCommand() *cli.Command {
+	var (
+		cfg        config
+		incidentID model.IncidentID
+		alertIDs   []string
+	)
+
+	flags := []cli.Flag{
+		incidentIDFlag("Incident ID to add alerts", &incidentID),
+		alertIDsFlag("Alert ID to add. Can be repeated", &alertIDs, true),
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "add",
+		Usage: "Add alerts to an incident",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			incident, err := uc.AddToIncident(ctx, incidentID, toAlertIDs(alertIDs))
+			if err != nil {
+				return goerr.Wrap(err, "failed to add alerts to incident")
+			}
+
+			fmt.Fprintf(c.Root().Writer, "Incident %s has %d alerts\n", incident.ID, len(incident.AlertIDs))
+			return nil
+		},
+	}
+}
+
+func incidentRemoveCommand() *cli.Command {
+	var (
+		cfg        config
+		incidentID model.IncidentID
+		alertIDs   []string
+	)
+
+	flags := []cli.Flag{
+		incidentIDFlag("Incident ID to remove alerts", &incidentID),
+		alertIDsFlag("Alert ID to remove. Can be repeated", &alertIDs, true),
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "remove",
+		Usage: "Remove alerts from an incident",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			incident, err := uc.RemoveFromIncident(ctx, incidentID, toAlertIDs(alertIDs))
+			if err != nil {
+				return goerr.Wrap(err, "failed to remove alerts from incident")
+			}
+
+			fmt.Fprintf(c.Root().Writer, "Incident %s has %d alerts\n", incident.ID, len(incident.AlertIDs))
+			return nil
+		},
+	}
+}
+
+func incidentListCommand() *cli.Command {
+	var (
+		cfg       config
+		status    string
+		pageToken string
+		limit     int64
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "status",
+			Aliases:     []string{"s"},
+			Usage:       "Filter by status (new, acknowledged, investigating, resolved, closed)",
+			Destination: &status,
+		},
+		&cli.IntFlag{
+			Name:        "limit",
+			Usage:       "Maximum number of incidents to list",
+			Value:       100,
+			Destination: &limit,
+		},
+		&cli.StringFlag{
+			Name:        "page-token",
+			Usage:       "Token to get the next page, printed by the previous incident list",
+			Destination: &pageToken,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List incidents",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			input := &repository.ListIncidentsInput{
+				Limit:     int(limit),
+				PageToken: pageToken,
+			}
+			if status != "" {
+				s := model.AlertStatus(status)
+				input.Status = &s
+			}
+
+			uc := alert.New(repo, nil)
+			incidents, nextPageToken, err := uc.ListIncidents(ctx, input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to list incidents")
+			}
+
+			defer printNextPageToken(c, nextPageToken)
+
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Incidents(incidents)
+			}
+
+			if len(incidents) == 0 {
+				fmt.Fprintf(c.Root().Writer, "No incidents found\n")
+				return nil
+			}
+			for _, x := range incidents {
+				fmt.Fprintf(c.Root().Writer, "%s\t%s\t%s\t%d alerts\n", x.ID, x.Title, x.CurrentStatus(), len(x.AlertIDs))
+			}
+
+			return nil
+		},
+	}
+}
+
+func incidentShowCommand() *cli.Command {
+	var (
+		cfg        config
+		incidentID model.IncidentID
+	)
+
+	flags := []cli.Flag{
+		incidentIDFlag("Incident ID to show", &incidentID),
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "show",
+		Usage: "Show an incident with its alerts and timeline",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil)
+			incident, alerts, activities, err := uc.ShowIncident(ctx, incidentID)
+			if err != nil {
+				return goerr.Wrap(err, "failed to show incident")
+			}
+
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Incident(incident, alerts, activities)
+			}
+
+			w := c.Root().Writer
+			fmt.Fprintf(w, "ID:          %s\n", incident.ID)
+			fmt.Fprintf(w, "Title:       %s\n", incident.Title)
+			if incident.Description != "" {
+				fmt.Fprintf(w, "Description: %s\n", incident.Description)
+			}
+			fmt.Fprintf(w, "Created:     %s\n", incident.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Fprintf(w, "Status:      %s\n", incident.CurrentStatus())
+			if incident.Assignee != "" {
+				fmt.Fprintf(w, "Assignee:    %s\n", incident.Assignee)
+			}
+
+			fmt.Fprintf(w, "Alerts:\n")
+			for _, a := range alerts {
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", a.ID, a.Title, a.CurrentStatus())
+			}
+
+			fmt.Fprintf(w, "Timeline:\n")
+			for _, a := range activities {
+				printActivity(w, a)
+			}
+
+			return nil
+		},
+	}
+}
+
+func incidentStatusCommand() *cli.Command {
+	var (
+		cfg        config
+		incidentID model.IncidentID
+		status     string
+		note       string
+	)
+
+	flags := []cli.Flag{
+		incidentIDFlag("Incident ID to update", &incidentID),
+		&cli.StringFlag{
+			Name:        "status",
+			Aliases:     []string{"s"},
+			Usage:       "New status (new, acknowledged, investigating, resolved, closed)",
+			Destination: &status,
+			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "note",
+			Aliases:     []string{"n"},
+			Usage:       "Reason of the status change",
+			Destination: &note,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Change status of an incident",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			incident, err := uc.SetIncidentStatus(ctx, incidentID, model.AlertStatus(status), note)
+			if err != nil {
+				return goerr.Wrap(err, "failed to change incident status")
+			}
+
+			fmt.Fprintf(c.Root().Writer, "Incident %s is now %s\n", incident.ID, incident.Status)
+			return nil
+		},
+	}
+}
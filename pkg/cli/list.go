@@ -3,18 +3,35 @@ package cli
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
 	"github.com/m-mizutani/leveret/pkg/usecase/alert"
 	"github.com/urfave/cli/v3"
 )
 
 func listCommand() *cli.Command {
 	var (
-		cfg    config
-		all    bool
-		offset int64
-		limit  int64
+		cfg        config
+		all        bool
+		merged     bool
+		resolved   bool
+		unresolved bool
+		statuses   []string
+		assignee   string
+		severity   string
+		conclusion string
+		since      string
+		until      string
+		keyword    string
+		attribute  string
+		order      string
+		pageToken  string
+		offset     int64
+		limit      int64
 	)
 
 	flags := []cli.Flag{
@@ -25,6 +42,81 @@ func listCommand() *cli.Command {
 			Sources:     cli.EnvVars("LEVERET_LIST_ALL"),
 			Destination: &all,
 		},
+		&cli.BoolFlag{
+			Name:        "merged",
+			Usage:       "Show only merged alerts",
+			Sources:     cli.EnvVars("LEVERET_LIST_MERGED"),
+			Destination: &merged,
+		},
+		&cli.BoolFlag{
+			Name:        "resolved",
+			Usage:       "Show only resolved alerts",
+			Sources:     cli.EnvVars("LEVERET_LIST_RESOLVED"),
+			Destination: &resolved,
+		},
+		&cli.BoolFlag{
+			Name:        "unresolved",
+			Usage:       "Show only unresolved alerts",
+			Sources:     cli.EnvVars("LEVERET_LIST_UNRESOLVED"),
+			Destination: &unresolved,
+		},
+		&cli.StringSliceFlag{
+			Name:        "status",
+			Aliases:     []string{"s"},
+			Usage:       "Filter by status (new, acknowledged, investigating, resolved, closed). Can be repeated",
+			Sources:     cli.EnvVars("LEVERET_LIST_STATUS"),
+			Destination: &statuses,
+		},
+		&cli.StringFlag{
+			Name:        "assignee",
+			Usage:       "Filter by assignee",
+			Sources:     cli.EnvVars("LEVERET_LIST_ASSIGNEE"),
+			Destination: &assignee,
+		},
+		&cli.StringFlag{
+			Name:        "severity",
+			Usage:       "Filter by severity (critical, high, medium, low, info)",
+			Sources:     cli.EnvVars("LEVERET_LIST_SEVERITY"),
+			Destination: &severity,
+		},
+		&cli.StringFlag{
+			Name:        "conclusion",
+			Usage:       "Filter by conclusion (unaffected, false_positive, true_positive)",
+			Sources:     cli.EnvVars("LEVERET_LIST_CONCLUSION"),
+			Destination: &conclusion,
+		},
+		&cli.StringFlag{
+			Name:        "since",
+			Usage:       "Show alerts created at or after the time (RFC3339, YYYY-MM-DD or duration like 24h)",
+			Sources:     cli.EnvVars("LEVERET_LIST_SINCE"),
+			Destination: &since,
+		},
+		&cli.StringFlag{
+			Name:        "until",
+			Usage:       "Show alerts created before the time (RFC3339, YYYY-MM-DD or duration like 24h)",
+			Sources:     cli.EnvVars("LEVERET_LIST_UNTIL"),
+			Destination: &until,
+		},
+		&cli.StringFlag{
+			Name:        "keyword",
+			Aliases:     []string{"k"},
+			Usage:       "Filter by a word in title",
+			Sources:     cli.EnvVars("LEVERET_LIST_KEYWORD"),
+			Destination: &keyword,
+		},
+		&cli.StringFlag{
+			Name:        "attr",
+			Usage:       "Filter by attribute in key=value format",
+			Sources:     cli.EnvVars("LEVERET_LIST_ATTR"),
+			Destination: &attribute,
+		},
+		&cli.StringFlag{
+			Name:        "sort",
+			Usage:       "Sort order by creation time (desc, asc)",
+			Value:       string(repository.SortDesc),
+			Sources:     cli.EnvVars("LEVERET_LIST_SORT"),
+			Destination: &order,
+		},
 		&cli.IntFlag{
 			Name:        "offset",
 			Usage:       "Offset for pagination",
@@ -32,6 +124,12 @@ func listCommand() *cli.Command {
 			Sources:     cli.EnvVars("LEVERET_LIST_OFFSET"),
 			Destination: &offset,
 		},
+		&cli.StringFlag{
+			Name:        "page-token",
+			Usage:       "Token to get the next page, printed by the previous list with the same filters",
+			Sources:     cli.EnvVars("LEVERET_LIST_PAGE_TOKEN"),
+			Destination: &pageToken,
+		},
 		&cli.IntFlag{
 			Name:        "limit",
 			Usage:       "Maximum number of alerts to list",
@@ -41,7 +139,6 @@ func listCommand() *cli.Command {
 		},
 	}
 	flags = append(flags, globalFlags(&cfg)...)
-	flags = append(flags, llmFlags(&cfg)...)
 
 	return &cli.Command{
 		Name:  "list",
@@ -54,29 +151,66 @@ func listCommand() *cli.Command {
 				return err
 			}
 
-			gemini, err := cfg.newGemini(ctx)
-			if err != nil {
-				return err
+			// Create alert usecase (LLM is not required for read-only operations)
+			uc := alert.New(repo, nil)
+
+			input := &repository.ListAlertsInput{
+				Statuses:     toStatuses(statuses),
+				Assignee:     assignee,
+				Severity:     model.Severity(severity),
+				Conclusion:   model.Conclusion(conclusion),
+				TitleKeyword: keyword,
+				Order:        repository.SortOrder(order),
+				Offset:       int(offset),
+				Limit:        int(limit),
+				PageToken:    pageToken,
 			}
 
-			// Create alert usecase
-			uc := alert.New(repo, gemini)
+			// Merged alerts are hidden unless --all or --merged is given
+			if merged || !all {
+				input.Merged = &merged
+			}
+
+			if resolved && unresolved {
+				return goerr.New("--resolved and --unresolved can not be used together")
+			}
+			if resolved || unresolved {
+				input.Resolved = &resolved
+			}
+
+			if input.CreatedAfter, err = parseTime(since); err != nil {
+				return goerr.Wrap(err, "invalid --since")
+			}
+			if input.CreatedBefore, err = parseTime(until); err != nil {
+				return goerr.Wrap(err, "invalid --until")
+			}
+
+			if attribute != "" {
+				key, value, ok := strings.Cut(attribute, "=")
+				if !ok {
+					return goerr.New("--attr must be key=value format", goerr.V("attr", attribute))
+				}
+				input.AttributeKey, input.AttributeValue = key, value
+			}
 
 			// List alerts
-			alerts, err := uc.List(ctx, alert.ListOptions{
-				IncludeMerged: all,
-				Offset:        int(offset),
-				Limit:         int(limit),
-			})
+			alerts, nextPageToken, err := uc.List(ctx, input)
 			if err != nil {
 				return goerr.Wrap(err, "failed to list alerts")
 			}
 
+			defer printNextPageToken(c, nextPageToken)
+
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Alerts(alerts)
+			}
+
 			// Display alerts
 			for _, a := range alerts {
-				status := "active"
-				if a.ResolvedAt != nil {
-					status = "resolved"
+				status := string(a.CurrentStatus())
+				if a.Assignee != "" {
+					status += " (" + a.Assignee + ")"
 				}
 				if a.MergedTo != "" {
 					status = fmt.Sprintf("merged to %s", a.MergedTo)
@@ -88,3 +222,29 @@ func listCommand() *cli.Command {
 		},
 	}
 }
+
+func toStatuses(values []string) []model.AlertStatus {
+	statuses := make([]model.AlertStatus, len(values))
+	for i, v := range values {
+		statuses[i] = model.AlertStatus(v)
+	}
+	return statuses
+}
+
+// parseTime parses an absolute time in RFC3339 or YYYY-MM-DD, or a duration
+// before now such as "24h". Empty value returns zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, goerr.New("unknown time format", goerr.V("value", value))
+}
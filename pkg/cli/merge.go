@@ -13,18 +13,17 @@ import (
 func mergeCommand() *cli.Command {
 	var (
 		cfg      config
-		sourceID model.AlertID
+		sel      alertSelector
 		targetID model.AlertID
 	)
 
 	flags := []cli.Flag{
-		&cli.StringFlag{
+		&cli.StringSliceFlag{
 			Name:        "source-id",
 			Aliases:     []string{"s"},
-			Usage:       "Source alert ID to merge from",
+			Usage:       "Source alert ID to merge from. Can be repeated",
 			Sources:     cli.EnvVars("LEVERET_MERGE_SOURCE_ID"),
-			Destination: (*string)(&sourceID),
-			Required:    true,
+			Destination: &sel.ids,
 		},
 		&cli.StringFlag{
 			Name:        "target-id",
@@ -35,12 +34,13 @@ func mergeCommand() *cli.Command {
 			Required:    true,
 		},
 	}
+	flags = append(flags, selectorFlags(&sel)...)
 	flags = append(flags, globalFlags(&cfg)...)
 	flags = append(flags, llmFlags(&cfg)...)
 
 	return &cli.Command{
 		Name:  "merge",
-		Usage: "Merge an alert into another",
+		Usage: "Merge alerts into another. Source alerts are given by IDs, stdin, filters or similarity",
 		Flags: flags,
 		Action: func(ctx context.Context, c *cli.Command) error {
 
@@ -56,15 +56,27 @@ func mergeCommand() *cli.Command {
 			}
 
 			// Create alert usecase
-			uc := alert.New(repo, gemini)
+			uc := alert.New(repo, gemini, alert.WithActor(cfg.actor))
 
-			// Merge alerts
-			if err := uc.Merge(ctx, sourceID, targetID); err != nil {
-				return goerr.Wrap(err, "failed to merge alerts")
+			// Target is also selected by filters or similarity to itself
+			alerts, err := sel.selectAlerts(ctx, c, uc, "merged into "+string(targetID), targetID)
+			if err != nil {
+				return err
 			}
 
-			fmt.Fprintf(c.Root().Writer, "Alert %s merged to %s\n", sourceID, targetID)
-			return nil
+			// Merge alerts
+			return applyAlerts(c, alerts, func(a *model.Alert) (string, error) {
+				mergedTo, err := uc.Merge(ctx, a.ID, targetID)
+				if err != nil {
+					return "", goerr.Wrap(err, "failed to merge alerts")
+				}
+
+				msg := fmt.Sprintf("Alert %s merged to %s", a.ID, mergedTo)
+				if mergedTo != targetID {
+					msg += fmt.Sprintf(" (%s is merged into %s)", targetID, mergedTo)
+				}
+				return msg, nil
+			})
 		},
 	}
 }
@@ -106,7 +118,7 @@ func unmergeCommand() *cli.Command {
 			}
 
 			// Create alert usecase
-			uc := alert.New(repo, gemini)
+			uc := alert.New(repo, gemini, alert.WithActor(cfg.actor))
 
 			// Unmerge alert
 			if err := uc.Unmerge(ctx, alertID); err != nil {
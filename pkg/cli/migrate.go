@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+)
+
+func migrateCommand() *cli.Command {
+	var (
+		cfg    config
+		dryRun bool
+	)
+
+	flags := []cli.Flag{
+		&cli.BoolFlag{
+			Name:        "dry-run",
+			Usage:       "Show alerts to be migrated without changing them",
+			Destination: &dryRun,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "Backfill status and keywords of alerts saved by older versions so that list filters match them",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			// LLM is not required to migrate stored fields
+			uc := alert.New(repo, nil)
+
+			migrated, err := uc.Migrate(ctx, dryRun)
+			if err != nil {
+				return goerr.Wrap(err, "failed to migrate alerts")
+			}
+
+			w := c.Root().Writer
+			for _, a := range migrated {
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", a.ID, a.Title, a.Status)
+			}
+			if dryRun {
+				fmt.Fprintf(w, "Dry run: %d alerts will be migrated\n", len(migrated))
+			} else {
+				fmt.Fprintf(w, "%d alerts migrated\n", len(migrated))
+			}
+			return nil
+		},
+	}
+}
@@ -2,31 +2,37 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/adapter"
 	"github.com/m-mizutani/leveret/pkg/agent/bigquery"
 	"github.com/m-mizutani/leveret/pkg/tool"
 	toolAlert "github.com/m-mizutani/leveret/pkg/tool/alert"
 	"github.com/m-mizutani/leveret/pkg/tool/otx"
 	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/usecase/ingest"
 	"github.com/m-mizutani/leveret/pkg/workflow"
 	"github.com/urfave/cli/v3"
 )
 
 func newCommand() *cli.Command {
 	var (
-		cfg       config
-		mcpCfg    mcpConfig
-		inputPath string
-		policyDir string
+		cfg         config
+		mcpCfg      mcpConfig
+		groupCfg    groupConfig
+		inputPath   string
+		policyDir   string
+		concurrency int64
 	)
 
 	// Create tool registry
 	registry := tool.New(
 		toolAlert.NewSearchAlerts(),
+		toolAlert.NewSearchByAttribute(),
+		toolAlert.NewFindAlerts(),
 		otx.New(),
 		bigquery.New(),
 	)
@@ -35,7 +41,7 @@ func newCommand() *cli.Command {
 		&cli.StringFlag{
 			Name:        "input",
 			Aliases:     []string{"i"},
-			Usage:       "Path to JSON file containing alert data",
+			Usage:       "Path to alert data: JSON, NDJSON or JSON array file, directory of such files, or '-' for stdin",
 			Sources:     cli.EnvVars("LEVERET_INPUT"),
 			Destination: &inputPath,
 		},
@@ -45,30 +51,27 @@ func newCommand() *cli.Command {
 			Sources:     cli.EnvVars("LEVERET_POLICY_DIR"),
 			Destination: &policyDir,
 		},
+		&cli.IntFlag{
+			Name:        "concurrency",
+			Usage:       "Number of alerts processed in parallel. Progress of policy workflow is shown only if 1",
+			Value:       1,
+			Sources:     cli.EnvVars("LEVERET_CONCURRENCY"),
+			Destination: &concurrency,
+		},
 	}
 	flags = append(flags, globalFlags(&cfg)...)
 	flags = append(flags, llmFlags(&cfg)...)
 	flags = append(flags, mcpFlags(&mcpCfg)...)
+	flags = append(flags, groupFlags(&groupCfg)...)
 	flags = append(flags, registry.Flags()...)
 
 	return &cli.Command{
 		Name:  "new",
-		Usage: "Create a new alert from JSON input",
+		Usage: "Create new alerts from JSON input",
 		Flags: flags,
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if inputPath == "" {
-				return goerr.New("input file path is required")
-			}
-
-			// Read JSON file
-			data, err := os.ReadFile(inputPath)
-			if err != nil {
-				return goerr.Wrap(err, "failed to read input file", goerr.Value("path", inputPath))
-			}
-
-			var alertData any
-			if err := json.Unmarshal(data, &alertData); err != nil {
-				return goerr.Wrap(err, "failed to parse JSON")
+				return goerr.New("input path is required")
 			}
 
 			// Initialize dependencies
@@ -77,10 +80,18 @@ func newCommand() *cli.Command {
 				return err
 			}
 
-			gemini, err := cfg.newGemini(ctx)
+			llm, err := cfg.newGemini(ctx)
 			if err != nil {
 				return err
 			}
+			gemini := adapter.NewUsageTracker(llm)
+			defer func() {
+				printUsage(c.Root().Writer, &cfg, gemini.Usage())
+			}()
+
+			opts := []ingest.Option{
+				ingest.WithConcurrency(int(concurrency)),
+			}
 
 			// Check if policy directory is specified
 			if policyDir != "" {
@@ -109,67 +120,74 @@ func newCommand() *cli.Command {
 					return goerr.Wrap(err, "failed to initialize tools")
 				}
 
-				engine, err := workflow.New(ctx, policyDir, gemini, registry)
+				// Progress messages of the engine are shown only when inputs
+				// are processed one by one, otherwise they interleave
+				output := c.Root().Writer
+				if concurrency > 1 {
+					output = io.Discard
+				}
+				engine, err := workflow.New(ctx, policyDir, gemini, registry, workflow.WithOutput(output))
 				if err != nil {
 					return goerr.Wrap(err, "failed to create workflow engine")
 				}
+				opts = append(opts, ingest.WithEngine(engine))
+			}
 
-				results, err := engine.Execute(ctx, alertData)
-				if err != nil {
-					return goerr.Wrap(err, "failed to execute workflow")
-				}
+			uc := ingest.New(alert.New(repo, gemini, groupCfg.option()), opts...)
+			w := c.Root().Writer
 
-				if len(results) == 0 {
-					fmt.Fprintf(c.Root().Writer, "No alerts generated (rejected by ingest policy)\n")
-					return nil
-				}
+			summary := uc.Run(ctx, ingest.ReadPath(inputPath, os.Stdin), func(result *ingest.Result) {
+				printIngestResult(w, result)
+			})
 
-				// Process workflow results
-				uc := alert.New(repo, gemini)
-				for _, result := range results {
-					fmt.Fprintf(c.Root().Writer, "Alert: %s\n", result.Alert.Title)
-
-					// Check triage action
-					if result.Triage != nil {
-						fmt.Fprintf(c.Root().Writer, "  Action: %s, Severity: %s\n", result.Triage.Action, result.Triage.Severity)
-						if result.Triage.Note != "" {
-							fmt.Fprintf(c.Root().Writer, "  Note: %s\n", result.Triage.Note)
-						}
-
-						// Handle based on triage action
-						switch result.Triage.Action {
-						case "discard":
-							fmt.Fprintf(c.Root().Writer, "  → Discarded (not saving to database)\n")
-							continue
-						case "notify":
-							fmt.Fprintf(c.Root().Writer, "  → Notification mode (saving with lower priority)\n")
-						case "accept":
-							fmt.Fprintf(c.Root().Writer, "  → Accepted (saving to database)\n")
-						default:
-							fmt.Fprintf(c.Root().Writer, "  → Unknown action '%s' (treating as accept)\n", result.Triage.Action)
-						}
-					}
-
-					// Use Insert to save the alert (generates ID, CreatedAt, embedding, and saves to repo)
-					newAlert, err := uc.Insert(ctx, result.Alert.Data)
-					if err != nil {
-						return goerr.Wrap(err, "failed to insert alert")
-					}
-
-					fmt.Fprintf(c.Root().Writer, "  Alert ID: %s\n", newAlert.ID)
-				}
-			} else {
-				// Direct insert without workflow
-				uc := alert.New(repo, gemini)
-				newAlert, err := uc.Insert(ctx, alertData)
-				if err != nil {
-					return goerr.Wrap(err, "failed to insert alert")
-				}
+			fmt.Fprintf(w, "\nProcessed %d input(s): %d accepted, %d notified, %d discarded, %d errored\n",
+				summary.Inputs, summary.Accepted, summary.Notified, summary.Discarded, summary.Errored)
 
-				fmt.Fprintf(c.Root().Writer, "Alert created: %s\n", newAlert.ID)
+			if summary.Errored > 0 {
+				return goerr.New("some inputs failed to be ingested", goerr.V("errored", summary.Errored))
 			}
-
 			return nil
 		},
 	}
 }
+
+// printIngestResult prints outcome of an input and alerts generated from it
+func printIngestResult(w io.Writer, result *ingest.Result) {
+	source := result.Source
+	if source == "" {
+		source = "(unknown)"
+	}
+
+	switch {
+	case result.Err != nil:
+		fmt.Fprintf(w, "[%s] ❌ Error: %v\n", source, result.Err)
+		return
+	case result.Rejected:
+		fmt.Fprintf(w, "[%s] No alerts generated (rejected by ingest policy)\n", source)
+		return
+	}
+
+	for _, a := range result.Alerts {
+		fmt.Fprintf(w, "[%s] Alert: %s\n", source, a.Title)
+		if a.Severity != "" {
+			fmt.Fprintf(w, "  Severity: %s\n", a.Severity)
+		}
+		if a.Note != "" {
+			fmt.Fprintf(w, "  Note: %s\n", a.Note)
+		}
+
+		switch a.Action {
+		case ingest.ActionDiscarded:
+			fmt.Fprintf(w, "  → Discarded (not saving to database)\n")
+		case ingest.ActionErrored:
+			fmt.Fprintf(w, "  → ❌ Failed to save: %v\n", a.Err)
+		case ingest.ActionNotified:
+			fmt.Fprintf(w, "  → Notification mode (saved with lower priority): %s\n", a.AlertID)
+		default:
+			fmt.Fprintf(w, "  → Accepted: %s\n", a.AlertID)
+		}
+		if a.MergedTo != "" {
+			fmt.Fprintf(w, "  → Grouped into: %s\n", a.MergedTo)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/urfave/cli/v3"
+)
+
+func pivotCommand() *cli.Command {
+	var (
+		cfg       config
+		attrType  string
+		value     string
+		limit     int64
+		pageToken string
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "value",
+			Usage:       "Indicator to search, e.g. IP address or domain",
+			Sources:     cli.EnvVars("LEVERET_PIVOT_VALUE"),
+			Destination: &value,
+			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "type",
+			Aliases:     []string{"t"},
+			Usage:       "Attribute type of the indicator (ip_address, domain, hash, url, string, number). All types if not given",
+			Sources:     cli.EnvVars("LEVERET_PIVOT_TYPE"),
+			Destination: &attrType,
+		},
+		&cli.IntFlag{
+			Name:        "limit",
+			Usage:       "Maximum number of alerts to list (max 100)",
+			Value:       100,
+			Sources:     cli.EnvVars("LEVERET_PIVOT_LIMIT"),
+			Destination: &limit,
+		},
+		&cli.StringFlag{
+			Name:        "page-token",
+			Usage:       "Token to get the next page, printed by the previous pivot with the same indicator",
+			Sources:     cli.EnvVars("LEVERET_PIVOT_PAGE_TOKEN"),
+			Destination: &pageToken,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "pivot",
+		Usage: "Find alerts having an indicator in attributes regardless of alert source",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			input := &repository.SearchAttributesInput{
+				Type:      model.AttributeType(attrType),
+				Value:     value,
+				Limit:     int(limit),
+				PageToken: pageToken,
+			}
+			alerts, nextPageToken, err := repo.SearchAlertsByAttribute(ctx, input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to search alerts by attribute")
+			}
+			defer printNextPageToken(c, nextPageToken)
+
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Alerts(alerts)
+			}
+
+			if len(alerts) == 0 {
+				fmt.Fprintf(c.Root().Writer, "No alerts found with %s\n", value)
+				return nil
+			}
+
+			// Show attribute keys of the indicator because they differ by source
+			for _, a := range alerts {
+				keys := matchedAttributeKeys(a, input.Type, value)
+				fmt.Fprintf(c.Root().Writer, "%s\t%s\t%s\t%s\n",
+					a.ID,
+					a.CreatedAt.Format("2006-01-02 15:04:05"),
+					a.Title,
+					strings.Join(keys, ","),
+				)
+			}
+
+			return nil
+		},
+	}
+}
+
+// matchedAttributeKeys returns keys of alert attributes having the indicator
+func matchedAttributeKeys(a *model.Alert, attrType model.AttributeType, value string) []string {
+	var keys []string
+	for _, attr := range a.Attributes {
+		if attrType != "" && attr.Type != attrType {
+			continue
+		}
+		if attr.Type.Normalize(attr.Value) == attr.Type.Normalize(value) {
+			keys = append(keys, attr.Key)
+		}
+	}
+	return keys
+}
@@ -1,36 +1,39 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/leveret/pkg/model"
 	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/usecase/chat"
 	"github.com/urfave/cli/v3"
 )
 
 func resolveCommand() *cli.Command {
 	var (
 		cfg        config
-		alertID    model.AlertID
+		sel        alertSelector
 		conclusion model.Conclusion
 		note       string
+		suggest    bool
 	)
 
 	flags := []cli.Flag{
-		&cli.StringFlag{
+		&cli.StringSliceFlag{
 			Name:        "alert-id",
 			Aliases:     []string{"i"},
-			Usage:       "Alert ID to resolve",
+			Usage:       "Alert ID to resolve. Can be repeated",
 			Sources:     cli.EnvVars("LEVERET_ALERT_ID"),
-			Destination: (*string)(&alertID),
-			Required:    true,
+			Destination: &sel.ids,
 		},
 		&cli.StringFlag{
 			Name:        "conclusion",
 			Aliases:     []string{"c"},
-			Usage:       "Conclusion (unaffected, false_positive, true_positive, inconclusive)",
+			Usage:       "Conclusion (unaffected, false_positive, true_positive)",
 			Value:       string(model.ConclusionUnaffected),
 			Sources:     cli.EnvVars("LEVERET_RESOLVE_CONCLUSION"),
 			Destination: (*string)(&conclusion),
@@ -42,13 +45,19 @@ func resolveCommand() *cli.Command {
 			Sources:     cli.EnvVars("LEVERET_RESOLVE_NOTE"),
 			Destination: &note,
 		},
+		&cli.BoolFlag{
+			Name:        "suggest",
+			Usage:       "Suggest conclusion and note from chat histories of the alert, then confirm or edit them",
+			Destination: &suggest,
+		},
 	}
+	flags = append(flags, selectorFlags(&sel)...)
 	flags = append(flags, globalFlags(&cfg)...)
 	flags = append(flags, llmFlags(&cfg)...)
 
 	return &cli.Command{
 		Name:  "resolve",
-		Usage: "Mark an alert as resolved",
+		Usage: "Mark alerts as resolved. Alerts are given by IDs, stdin, filters or similarity",
 		Flags: flags,
 		Action: func(ctx context.Context, c *cli.Command) error {
 
@@ -64,15 +73,110 @@ func resolveCommand() *cli.Command {
 			}
 
 			// Create alert usecase
-			uc := alert.New(repo, gemini)
+			uc := alert.New(repo, gemini, alert.WithActor(cfg.actor))
+
+			if suggest {
+				if len(sel.ids) != 1 || sel.stdin || sel.filtered() || sel.similarTo != "" {
+					return goerr.New("--suggest requires exactly one --alert-id")
+				}
+				if c.IsSet("conclusion") || c.IsSet("note") {
+					return goerr.New("--suggest can not be used with --conclusion or --note")
+				}
 
-			// Resolve alert
-			if err := uc.Resolve(ctx, alertID, conclusion, note); err != nil {
-				return goerr.Wrap(err, "failed to resolve alert")
+				storage, err := cfg.newStorage(ctx)
+				if err != nil {
+					return err
+				}
+
+				suggestion, err := chat.SuggestResolution(ctx, chat.SuggestInput{
+					Repo:    repo,
+					Storage: storage,
+					Gemini:  gemini,
+					AlertID: model.AlertID(sel.ids[0]),
+				})
+				if err != nil {
+					return goerr.Wrap(err, "failed to suggest resolution")
+				}
+
+				suggestion, err = reviewSuggestion(c, suggestion, sel.yes || sel.dryRun)
+				if err != nil {
+					return err
+				}
+				if suggestion == nil {
+					return nil
+				}
+				conclusion, note = suggestion.Conclusion, suggestion.Note
 			}
 
-			fmt.Fprintf(c.Root().Writer, "Alert resolved: %s\n", alertID)
-			return nil
+			alerts, err := sel.selectAlerts(ctx, c, uc, "resolved as "+string(conclusion))
+			if err != nil {
+				return err
+			}
+
+			// Resolve alerts
+			return applyAlerts(c, alerts, func(a *model.Alert) (string, error) {
+				if err := uc.Resolve(ctx, a.ID, conclusion, note); err != nil {
+					return "", goerr.Wrap(err, "failed to resolve alert")
+				}
+				return fmt.Sprintf("Alert resolved: %s", a.ID), nil
+			})
 		},
 	}
 }
+
+// reviewSuggestion shows the suggested resolution and lets the analyst
+// accept, edit or reject it. It returns nil if rejected. The suggestion is
+// accepted as is if skip is true.
+func reviewSuggestion(c *cli.Command, suggestion *chat.Suggestion, skip bool) (*chat.Suggestion, error) {
+	w := c.Root().Writer
+	fmt.Fprintf(w, "Suggested conclusion: %s\n", suggestion.Conclusion)
+	fmt.Fprintf(w, "Suggested note:\n%s\n", indent(suggestion.Note, "  "))
+	if skip {
+		return suggestion, nil
+	}
+
+	r := bufio.NewReader(c.Root().Reader)
+	fmt.Fprintf(w, "Save this resolution? [y]es / [e]dit / [N]o: ")
+	answer, err := readLine(r)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read confirmation")
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return suggestion, nil
+	case "e", "edit":
+	default:
+		fmt.Fprintf(w, "Canceled\n")
+		return nil, nil
+	}
+
+	edited := *suggestion
+	for {
+		fmt.Fprintf(w, "Conclusion (unaffected, false_positive, true_positive) [%s]: ", edited.Conclusion)
+		input, err := readLine(r)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to read conclusion")
+		}
+		if input == "" {
+			break
+		}
+		if err := model.Conclusion(input).Validate(); err != nil {
+			fmt.Fprintf(w, "Invalid conclusion: %s\n", input)
+			continue
+		}
+		edited.Conclusion = model.Conclusion(input)
+		break
+	}
+
+	fmt.Fprintf(w, "Note (empty to keep the suggested note): ")
+	input, err := readLine(r)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read note")
+	}
+	if input != "" {
+		edited.Note = input
+	}
+
+	return &edited, nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/m-mizutani/leveret/pkg/usecase/alert"
 	"github.com/urfave/cli/v3"
@@ -52,8 +53,14 @@ func searchCommand() *cli.Command {
 				return err
 			}
 
-			// Create alert usecase
-			uc := alert.New(repo, gemini, alert.WithOutput(c.Root().Writer))
+			out := newPrinter(c)
+
+			// Create alert usecase. Progress messages are suppressed in structured output.
+			var progress io.Writer = c.Root().Writer
+			if out.structured() {
+				progress = io.Discard
+			}
+			uc := alert.New(repo, gemini, alert.WithOutput(progress))
 
 			// Search for similar alerts
 			alerts, err := uc.Search(ctx, alert.SearchOptions{
@@ -64,6 +71,10 @@ func searchCommand() *cli.Command {
 				return err
 			}
 
+			if out.structured() {
+				return out.Alerts(alerts)
+			}
+
 			// Display results
 			if len(alerts) == 0 {
 				fmt.Fprintf(c.Root().Writer, "No similar alerts found\n")
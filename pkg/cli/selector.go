@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+)
+
+// alertSelector holds flags to select target alerts of bulk operations.
+// Alert IDs are given by a flag of each command, e.g. --alert-id, or by
+// stdin. Otherwise filter flags or --similar-to select alerts.
+type alertSelector struct {
+	ids   []string
+	stdin bool
+
+	statuses   []string
+	assignee   string
+	severity   string
+	conclusion string
+	since      string
+	until      string
+	keyword    string
+	attribute  string
+
+	similarTo string
+	threshold float64
+
+	limit  int64
+	dryRun bool
+	yes    bool
+}
+
+// selectorFlags returns flags to select alerts with destination selector.
+// Filter flags have the "filter-" prefix so as not to conflict with flags of
+// the operation, e.g. --conclusion of resolve.
+func selectorFlags(sel *alertSelector) []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:        "stdin",
+			Usage:       "Read alert IDs from stdin, one per line. The first column is used, so output of list can be piped",
+			Destination: &sel.stdin,
+		},
+		&cli.StringSliceFlag{
+			Name:        "filter-status",
+			Usage:       "Select alerts by status (new, acknowledged, investigating, resolved, closed). Can be repeated",
+			Destination: &sel.statuses,
+		},
+		&cli.StringFlag{
+			Name:        "filter-assignee",
+			Usage:       "Select alerts by assignee",
+			Destination: &sel.assignee,
+		},
+		&cli.StringFlag{
+			Name:        "filter-severity",
+			Usage:       "Select alerts by severity (critical, high, medium, low, info)",
+			Destination: &sel.severity,
+		},
+		&cli.StringFlag{
+			Name:        "filter-conclusion",
+			Usage:       "Select alerts by conclusion (unaffected, false_positive, true_positive)",
+			Destination: &sel.conclusion,
+		},
+		&cli.StringFlag{
+			Name:        "filter-since",
+			Usage:       "Select alerts created at or after the time (RFC3339, YYYY-MM-DD or duration like 24h)",
+			Destination: &sel.since,
+		},
+		&cli.StringFlag{
+			Name:        "filter-until",
+			Usage:       "Select alerts created before the time (RFC3339, YYYY-MM-DD or duration like 24h)",
+			Destination: &sel.until,
+		},
+		&cli.StringFlag{
+			Name:        "filter-keyword",
+			Usage:       "Select alerts by a word in title",
+			Destination: &sel.keyword,
+		},
+		&cli.StringFlag{
+			Name:        "filter-attr",
+			Usage:       "Select alerts by attribute in key=value format",
+			Destination: &sel.attribute,
+		},
+		&cli.StringFlag{
+			Name:        "similar-to",
+			Usage:       "Select the alert and unmerged alerts similar to it",
+			Destination: &sel.similarTo,
+		},
+		&cli.FloatFlag{
+			Name:        "similar-threshold",
+			Usage:       "Cosine distance threshold for --similar-to (0.0-2.0, lower is more similar)",
+			Value:       0.1,
+			Destination: &sel.threshold,
+		},
+		&cli.IntFlag{
+			Name:        "limit",
+			Usage:       "Maximum number of selected alerts. The command fails if more alerts are selected",
+			Value:       100,
+			Destination: &sel.limit,
+		},
+		&cli.BoolFlag{
+			Name:        "dry-run",
+			Usage:       "Show selected alerts without changing them",
+			Destination: &sel.dryRun,
+		},
+		&cli.BoolFlag{
+			Name:        "yes",
+			Aliases:     []string{"y"},
+			Usage:       "Skip confirmation",
+			Destination: &sel.yes,
+		},
+	}
+}
+
+// filtered returns true if any filter flag is given
+func (sel *alertSelector) filtered() bool {
+	return len(sel.statuses) > 0 || sel.assignee != "" || sel.severity != "" || sel.conclusion != "" ||
+		sel.since != "" || sel.until != "" || sel.keyword != "" || sel.attribute != ""
+}
+
+// selector builds the selector of the usecase. IDs in stdin are read from r.
+func (sel *alertSelector) selector(r io.Reader) (*alert.Selector, error) {
+	s := &alert.Selector{
+		IDs:       toAlertIDs(sel.ids),
+		SimilarTo: model.AlertID(sel.similarTo),
+		Threshold: sel.threshold,
+		Limit:     int(sel.limit),
+	}
+
+	if sel.stdin {
+		ids, err := readAlertIDs(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, goerr.New("no alert ID in stdin")
+		}
+		s.IDs = append(s.IDs, ids...)
+	}
+
+	if sel.filtered() {
+		// Merged alerts are not selected as list hides them by default
+		notMerged := false
+		input := &repository.ListAlertsInput{
+			Statuses:     toStatuses(sel.statuses),
+			Assignee:     sel.assignee,
+			Severity:     model.Severity(sel.severity),
+			Conclusion:   model.Conclusion(sel.conclusion),
+			Merged:       &notMerged,
+			TitleKeyword: sel.keyword,
+		}
+
+		var err error
+		if input.CreatedAfter, err = parseTime(sel.since); err != nil {
+			return nil, goerr.Wrap(err, "invalid --filter-since")
+		}
+		if input.CreatedBefore, err = parseTime(sel.until); err != nil {
+			return nil, goerr.Wrap(err, "invalid --filter-until")
+		}
+		if sel.attribute != "" {
+			key, value, ok := strings.Cut(sel.attribute, "=")
+			if !ok {
+				return nil, goerr.New("--filter-attr must be key=value format", goerr.V("attr", sel.attribute))
+			}
+			input.AttributeKey, input.AttributeValue = key, value
+		}
+		s.Filter = input
+	}
+
+	return s, nil
+}
+
+// readAlertIDs reads the first column of each line as an alert ID. Empty
+// lines are ignored.
+func readAlertIDs(r io.Reader) ([]model.AlertID, error) {
+	var ids []model.AlertID
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		ids = append(ids, model.AlertID(fields[0]))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, goerr.Wrap(err, "failed to read alert IDs from stdin")
+	}
+	return ids, nil
+}
+
+// selectAlerts selects target alerts, shows them and asks confirmation
+// unless --yes is given. It returns no alerts if nothing should be done,
+// e.g. in dry run. A single alert given by ID is processed without
+// confirmation as before bulk operations were supported. Alerts in exclude
+// are dropped from results of filters and similarity, e.g. merge target.
+func (sel *alertSelector) selectAlerts(ctx context.Context, c *cli.Command, uc *alert.UseCase, action string, exclude ...model.AlertID) ([]*model.Alert, error) {
+	// stdin is consumed by alert IDs, so it can not be used to confirm
+	if sel.stdin && !sel.yes && !sel.dryRun {
+		return nil, goerr.New("--yes or --dry-run is required to read alert IDs from stdin")
+	}
+
+	r := c.Root().Reader
+	s, err := sel.selector(r)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := uc.Select(ctx, s)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to select alerts")
+	}
+	if len(s.IDs) == 0 {
+		alerts = slices.DeleteFunc(alerts, func(a *model.Alert) bool {
+			return slices.Contains(exclude, a.ID)
+		})
+	}
+
+	w := c.Root().Writer
+	if len(alerts) == 0 {
+		fmt.Fprintf(w, "No alerts selected\n")
+		return nil, nil
+	}
+
+	single := len(sel.ids) == 1 && !sel.stdin && len(alerts) == 1
+	if single && !sel.dryRun {
+		return alerts, nil
+	}
+
+	fmt.Fprintf(w, "%d alerts will be %s:\n", len(alerts), action)
+	for _, a := range alerts {
+		status := string(a.CurrentStatus())
+		if a.MergedTo != "" {
+			status = fmt.Sprintf("merged to %s", a.MergedTo)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", a.ID, a.Title, status)
+	}
+
+	if sel.dryRun {
+		fmt.Fprintf(w, "Dry run: no alerts were changed\n")
+		return nil, nil
+	}
+	if sel.yes {
+		return alerts, nil
+	}
+
+	fmt.Fprintf(w, "Proceed? [y/N]: ")
+	answer, err := readLine(bufio.NewReader(r))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read confirmation")
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return alerts, nil
+	default:
+		fmt.Fprintf(w, "Canceled\n")
+		return nil, nil
+	}
+}
+
+// readLine reads a line of interactive input without surrounding spaces.
+// EOF is treated as an empty answer.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// applyAlerts runs the operation for each alert and reports results. It
+// continues on failure and returns an error if any operation failed.
+func applyAlerts(c *cli.Command, alerts []*model.Alert, op func(a *model.Alert) (string, error)) error {
+	var failed int
+	for _, a := range alerts {
+		msg, err := op(a)
+		if err != nil {
+			failed++
+			fmt.Fprintf(c.Root().ErrWriter, "Failed: %s: %s\n", a.ID, err.Error())
+			continue
+		}
+		fmt.Fprintf(c.Root().Writer, "%s\n", msg)
+	}
+
+	if failed > 0 {
+		return goerr.New("operation failed for some alerts",
+			goerr.V("failed", failed),
+			goerr.V("total", len(alerts)),
+		)
+	}
+	return nil
+}
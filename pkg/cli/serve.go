@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/agent/bigquery"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/server"
+	"github.com/m-mizutani/leveret/pkg/tool"
+	toolAlert "github.com/m-mizutani/leveret/pkg/tool/alert"
+	"github.com/m-mizutani/leveret/pkg/tool/otx"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/usecase/ingest"
+	"github.com/m-mizutani/leveret/pkg/utils/logging"
+	"github.com/m-mizutani/leveret/pkg/workflow"
+	"github.com/urfave/cli/v3"
+)
+
+func serveCommand() *cli.Command {
+	var (
+		cfg         config
+		mcpCfg      mcpConfig
+		groupCfg    groupConfig
+		addr        string
+		policies    []string
+		concurrency int64
+	)
+
+	// Create tool registry
+	registry := tool.New(
+		toolAlert.NewSearchAlerts(),
+		toolAlert.NewSearchByAttribute(),
+		toolAlert.NewFindAlerts(),
+		otx.New(),
+		bigquery.New(),
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "addr",
+			Usage:       "Listen address of HTTP server",
+			Value:       "127.0.0.1:8080",
+			Sources:     cli.EnvVars("LEVERET_ADDR"),
+			Destination: &addr,
+		},
+		&cli.StringSliceFlag{
+			Name:        "policy",
+			Usage:       "Alert source and its policy directory as 'source=dir'. Alerts are accepted at POST /alert/{source}",
+			Sources:     cli.EnvVars("LEVERET_POLICY"),
+			Destination: &policies,
+			Required:    true,
+		},
+		&cli.IntFlag{
+			Name:        "concurrency",
+			Usage:       "Number of alerts processed in parallel per request",
+			Value:       4,
+			Sources:     cli.EnvVars("LEVERET_CONCURRENCY"),
+			Destination: &concurrency,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+	flags = append(flags, llmFlags(&cfg)...)
+	flags = append(flags, mcpFlags(&mcpCfg)...)
+	flags = append(flags, groupFlags(&groupCfg)...)
+	flags = append(flags, registry.Flags()...)
+
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "Run HTTP server to receive alerts via webhook",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			policyDirs, err := parsePolicies(policies)
+			if err != nil {
+				return err
+			}
+
+			// Initialize dependencies
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			gemini, err := cfg.newGemini(ctx)
+			if err != nil {
+				return err
+			}
+
+			storage, err := cfg.newStorage(ctx)
+			if err != nil {
+				return err
+			}
+
+			// Load and initialize MCP if configured
+			mcpProvider, err := mcpCfg.newMCP(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to initialize MCP")
+			}
+			if mcpProvider != nil {
+				registry.AddTool(mcpProvider)
+			}
+
+			if err := registry.Init(ctx, &tool.Client{
+				Repo:    repo,
+				Gemini:  gemini,
+				Storage: storage,
+			}); err != nil {
+				return goerr.Wrap(err, "failed to initialize tools")
+			}
+
+			// Build workflow engine for each alert source
+			alertUC := alert.New(repo, gemini, groupCfg.option())
+			sources := make(map[string]*ingest.UseCase, len(policyDirs))
+			for source, dir := range policyDirs {
+				// Progress messages of concurrent requests would interleave
+				engine, err := workflow.New(ctx, dir, gemini, registry, workflow.WithOutput(io.Discard))
+				if err != nil {
+					return goerr.Wrap(err, "failed to create workflow engine", goerr.V("source", source), goerr.V("dir", dir))
+				}
+				sources[source] = ingest.New(alertUC,
+					ingest.WithEngine(engine),
+					ingest.WithConcurrency(int(concurrency)),
+				)
+			}
+
+			handler := server.New(sources,
+				server.WithReadiness(func(ctx context.Context) error {
+					_, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 1})
+					return err
+				}),
+			)
+
+			ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			httpServer := &http.Server{
+				Addr:              addr,
+				Handler:           handler,
+				ReadHeaderTimeout: 10 * time.Second,
+				BaseContext:       func(net.Listener) context.Context { return ctx },
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				logging.From(ctx).Info("starting HTTP server", "addr", addr, "sources", policyDirs)
+				if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					errCh <- err
+				}
+				close(errCh)
+			}()
+
+			select {
+			case err := <-errCh:
+				if err != nil {
+					return goerr.Wrap(err, "failed to run HTTP server", goerr.V("addr", addr))
+				}
+				return nil
+
+			case <-ctx.Done():
+				fmt.Fprintf(c.Root().Writer, "Shutting down HTTP server...\n")
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+				if err := httpServer.Shutdown(shutdownCtx); err != nil {
+					return goerr.Wrap(err, "failed to shutdown HTTP server")
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// parsePolicies parses 'source=dir' pairs into a map
+func parsePolicies(policies []string) (map[string]string, error) {
+	dirs := make(map[string]string, len(policies))
+	for _, p := range policies {
+		source, dir, ok := strings.Cut(p, "=")
+		source = strings.TrimSpace(source)
+		dir = strings.TrimSpace(dir)
+		if !ok || source == "" || dir == "" {
+			return nil, goerr.New("invalid policy format, expected 'source=dir'", goerr.V("policy", p))
+		}
+		if _, exists := dirs[source]; exists {
+			return nil, goerr.New("duplicated alert source", goerr.V("source", source))
+		}
+		dirs[source] = dir
+	}
+	return dirs, nil
+}
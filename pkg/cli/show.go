@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/leveret/pkg/model"
 	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/utils/logging"
 	"github.com/urfave/cli/v3"
 )
 
@@ -28,7 +31,6 @@ func showCommand() *cli.Command {
 		},
 	}
 	flags = append(flags, globalFlags(&cfg)...)
-	flags = append(flags, llmFlags(&cfg)...)
 
 	return &cli.Command{
 		Name:  "show",
@@ -42,13 +44,8 @@ func showCommand() *cli.Command {
 				return err
 			}
 
-			gemini, err := cfg.newGemini(ctx)
-			if err != nil {
-				return err
-			}
-
-			// Create alert usecase
-			uc := alert.New(repo, gemini)
+			// Create alert usecase (LLM is not required for read-only operations)
+			uc := alert.New(repo, nil)
 
 			// Show alert
 			a, err := uc.Show(ctx, alertID)
@@ -56,14 +53,97 @@ func showCommand() *cli.Command {
 				return goerr.Wrap(err, "failed to show alert")
 			}
 
+			// Merge tree is supplementary, and querying it requires the
+			// MergedTo index of Firestore. Failure does not block showing
+			// the alert.
+			tree, err := uc.MergeTree(ctx, alertID)
+			if err != nil {
+				logging.From(ctx).Warn("failed to get merge tree", "error", err)
+				tree = nil
+			}
+
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Alert(a, tree)
+			}
+
 			// Display alert details
-			data, err := json.MarshalIndent(a, "", "  ")
+			w := c.Root().Writer
+			fmt.Fprintf(w, "ID:          %s\n", a.ID)
+			fmt.Fprintf(w, "Title:       %s\n", a.Title)
+			fmt.Fprintf(w, "Description: %s\n", a.Description)
+			fmt.Fprintf(w, "Created:     %s\n", a.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Fprintf(w, "Status:      %s\n", a.CurrentStatus())
+			if a.Assignee != "" {
+				fmt.Fprintf(w, "Assignee:    %s\n", a.Assignee)
+			}
+			if a.Severity != "" {
+				fmt.Fprintf(w, "Severity:    %s\n", a.Severity)
+			}
+			if a.ResolvedAt != nil {
+				fmt.Fprintf(w, "Resolved:    %s\n", a.ResolvedAt.Format("2006-01-02 15:04:05"))
+				fmt.Fprintf(w, "Conclusion:  %s\n", a.Conclusion)
+			}
+			if a.Note != "" {
+				fmt.Fprintf(w, "Note:        %s\n", a.Note)
+			}
+			if a.MergedTo != "" {
+				fmt.Fprintf(w, "Merged to:   %s\n", a.MergedTo)
+			}
+			if tree != nil {
+				fmt.Fprintf(w, "Merge tree:\n")
+				printMergeTree(w, tree, alertID, "  ")
+			}
+
+			if len(a.Attributes) > 0 {
+				fmt.Fprintf(w, "Attributes:\n")
+				for _, attr := range a.Attributes {
+					fmt.Fprintf(w, "  %s\t%s\t(%s)\n", attr.Key, attr.Value, attr.Type)
+				}
+			}
+
+			if a.TriageAction != "" {
+				fmt.Fprintf(w, "Triage:      %s\n", a.TriageAction)
+				if a.TriageNote != "" {
+					fmt.Fprintf(w, "Triage note: %s\n", a.TriageNote)
+				}
+			}
+			if len(a.Enrichments) > 0 {
+				fmt.Fprintf(w, "Enrichments:\n")
+				for _, e := range a.Enrichments {
+					fmt.Fprintf(w, "  [%s]\n%s\n", e.ID, indent(e.Result, "    "))
+				}
+			}
+
+			data, err := json.MarshalIndent(a.Data, "", "  ")
 			if err != nil {
-				return goerr.Wrap(err, "failed to marshal alert")
+				return goerr.Wrap(err, "failed to marshal alert data")
 			}
+			fmt.Fprintf(w, "Data:\n%s\n", string(data))
 
-			fmt.Fprintf(c.Root().Writer, "%s\n", string(data))
 			return nil
 		},
 	}
 }
+
+// printMergeTree writes the alert and alerts merged into it recursively. The
+// shown alert is marked.
+func printMergeTree(w io.Writer, node *alert.MergeNode, shown model.AlertID, prefix string) {
+	mark := ""
+	if node.Alert.ID == shown {
+		mark = " *"
+	}
+	fmt.Fprintf(w, "%s%s\t%s\t%s%s\n", prefix, node.Alert.ID, node.Alert.Title, node.Alert.CurrentStatus(), mark)
+	for _, child := range node.Merged {
+		printMergeTree(w, child, shown, prefix+"  ")
+	}
+}
+
+// indent prefixes each line of text
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
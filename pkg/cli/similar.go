@@ -120,6 +120,11 @@ func similarCommand() *cli.Command {
 				filtered = filtered[:limit]
 			}
 
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Alerts(filtered)
+			}
+
 			// Display results
 			if len(filtered) == 0 {
 				fmt.Fprintf(c.Root().Writer, "No similar alerts found\n")
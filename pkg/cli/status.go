@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+)
+
+func alertIDFlag(usage string, alertID *model.AlertID) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "alert-id",
+		Aliases:     []string{"i"},
+		Usage:       usage,
+		Sources:     cli.EnvVars("LEVERET_ALERT_ID"),
+		Destination: (*string)(alertID),
+		Required:    true,
+	}
+}
+
+func ackCommand() *cli.Command {
+	var (
+		cfg      config
+		sel      alertSelector
+		severity string
+	)
+
+	flags := []cli.Flag{
+		alertIDsFlag("Alert ID to acknowledge. Can be repeated", &sel.ids, false),
+		&cli.StringFlag{
+			Name:        "severity",
+			Usage:       "Override severity (critical, high, medium, low, info)",
+			Sources:     cli.EnvVars("LEVERET_ACK_SEVERITY"),
+			Destination: &severity,
+		},
+	}
+	flags = append(flags, selectorFlags(&sel)...)
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "ack",
+		Usage: "Acknowledge new alerts. Alerts are given by IDs, stdin, filters or similarity",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			alerts, err := sel.selectAlerts(ctx, c, uc, "acknowledged")
+			if err != nil {
+				return err
+			}
+
+			return applyAlerts(c, alerts, func(a *model.Alert) (string, error) {
+				acked, err := uc.Acknowledge(ctx, a.ID, model.Severity(severity))
+				if err != nil {
+					return "", goerr.Wrap(err, "failed to acknowledge alert")
+				}
+				return fmt.Sprintf("Alert acknowledged: %s (severity: %s)", acked.ID, acked.Severity), nil
+			})
+		},
+	}
+}
+
+func assignCommand() *cli.Command {
+	var (
+		cfg      config
+		sel      alertSelector
+		assignee string
+	)
+
+	flags := []cli.Flag{
+		alertIDsFlag("Alert ID to assign. Can be repeated", &sel.ids, false),
+		&cli.StringFlag{
+			Name:        "assignee",
+			Aliases:     []string{"u"},
+			Usage:       "Analyst who owns the alert",
+			Sources:     cli.EnvVars("LEVERET_ASSIGNEE"),
+			Destination: &assignee,
+			Required:    true,
+		},
+	}
+	flags = append(flags, selectorFlags(&sel)...)
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "assign",
+		Usage: "Assign alerts to an analyst and start investigation. Alerts are given by IDs, stdin, filters or similarity",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			alerts, err := sel.selectAlerts(ctx, c, uc, "assigned to "+assignee)
+			if err != nil {
+				return err
+			}
+
+			return applyAlerts(c, alerts, func(a *model.Alert) (string, error) {
+				assigned, err := uc.Assign(ctx, a.ID, assignee)
+				if err != nil {
+					return "", goerr.Wrap(err, "failed to assign alert")
+				}
+				return fmt.Sprintf("Alert %s assigned to %s (status: %s)", assigned.ID, assigned.Assignee, assigned.Status), nil
+			})
+		},
+	}
+}
+
+func closeCommand() *cli.Command {
+	var (
+		cfg  config
+		sel  alertSelector
+		note string
+	)
+
+	flags := []cli.Flag{
+		alertIDsFlag("Alert ID to close. Can be repeated", &sel.ids, false),
+		&cli.StringFlag{
+			Name:        "note",
+			Aliases:     []string{"n"},
+			Usage:       "Reason to close the alert",
+			Sources:     cli.EnvVars("LEVERET_CLOSE_NOTE"),
+			Destination: &note,
+		},
+	}
+	flags = append(flags, selectorFlags(&sel)...)
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "close",
+		Usage: "Close alerts without a conclusion. Alerts are given by IDs, stdin, filters or similarity",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			alerts, err := sel.selectAlerts(ctx, c, uc, "closed")
+			if err != nil {
+				return err
+			}
+
+			return applyAlerts(c, alerts, func(a *model.Alert) (string, error) {
+				if _, err := uc.Close(ctx, a.ID, note); err != nil {
+					return "", goerr.Wrap(err, "failed to close alert")
+				}
+				return fmt.Sprintf("Alert closed: %s", a.ID), nil
+			})
+		},
+	}
+}
+
+func reopenCommand() *cli.Command {
+	var (
+		cfg config
+		sel alertSelector
+	)
+
+	flags := []cli.Flag{
+		alertIDsFlag("Alert ID to reopen. Can be repeated", &sel.ids, false),
+	}
+	flags = append(flags, selectorFlags(&sel)...)
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "reopen",
+		Usage: "Reopen resolved or closed alerts. Alerts are given by IDs, stdin, filters or similarity",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			alerts, err := sel.selectAlerts(ctx, c, uc, "reopened")
+			if err != nil {
+				return err
+			}
+
+			return applyAlerts(c, alerts, func(a *model.Alert) (string, error) {
+				if _, err := uc.Reopen(ctx, a.ID); err != nil {
+					return "", goerr.Wrap(err, "failed to reopen alert")
+				}
+				return fmt.Sprintf("Alert reopened: %s", a.ID), nil
+			})
+		},
+	}
+}
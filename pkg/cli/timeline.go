@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+)
+
+func timelineCommand() *cli.Command {
+	var (
+		cfg     config
+		alertID model.AlertID
+	)
+
+	flags := []cli.Flag{
+		alertIDFlag("Alert ID to show timeline", &alertID),
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "timeline",
+		Usage: "Show activity timeline of an alert",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil)
+			activities, err := uc.Timeline(ctx, alertID)
+			if err != nil {
+				return goerr.Wrap(err, "failed to get timeline")
+			}
+
+			out := newPrinter(c)
+			if out.structured() {
+				return out.Activities(activities)
+			}
+
+			w := c.Root().Writer
+			if len(activities) == 0 {
+				fmt.Fprintf(w, "No activity recorded for alert %s\n", alertID)
+				return nil
+			}
+			for _, a := range activities {
+				printActivity(w, a)
+			}
+
+			return nil
+		},
+	}
+}
+
+func noteCommand() *cli.Command {
+	var (
+		cfg     config
+		alertID model.AlertID
+		message string
+	)
+
+	flags := []cli.Flag{
+		alertIDFlag("Alert ID to add a note", &alertID),
+		&cli.StringFlag{
+			Name:        "message",
+			Aliases:     []string{"m"},
+			Usage:       "Note to add to the alert timeline",
+			Destination: &message,
+			Required:    true,
+		},
+	}
+	flags = append(flags, globalFlags(&cfg)...)
+
+	return &cli.Command{
+		Name:  "note",
+		Usage: "Add a note to the activity timeline of an alert",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repo, err := cfg.newRepository()
+			if err != nil {
+				return err
+			}
+
+			uc := alert.New(repo, nil, alert.WithActor(cfg.actor))
+			if err := uc.AddNote(ctx, alertID, message); err != nil {
+				return goerr.Wrap(err, "failed to add note")
+			}
+
+			fmt.Fprintf(c.Root().Writer, "Note added to alert %s\n", alertID)
+			return nil
+		},
+	}
+}
+
+// printActivity writes an activity as a line of timeline
+func printActivity(w io.Writer, a *model.Activity) {
+	actor := a.Actor
+	if actor == "" {
+		actor = "(system)"
+	}
+
+	var detail string
+	switch a.Type {
+	case model.ActivityCreated:
+		detail = "alert created"
+		if a.AlertID == "" {
+			detail = "incident created"
+		}
+	case model.ActivityTriaged:
+		detail = fmt.Sprintf("triaged as %s", a.To)
+	case model.ActivityStatusChanged:
+		detail = fmt.Sprintf("status %s -> %s", a.From, a.To)
+	case model.ActivityResolved:
+		detail = fmt.Sprintf("resolved as %s (was %s)", a.To, a.From)
+	case model.ActivityAssigned:
+		if a.From == "" {
+			detail = fmt.Sprintf("assigned to %s", a.To)
+		} else {
+			detail = fmt.Sprintf("reassigned %s -> %s", a.From, a.To)
+		}
+	case model.ActivitySeverityChanged:
+		detail = fmt.Sprintf("severity %s -> %s", a.From, a.To)
+	case model.ActivityMergedInto:
+		detail = fmt.Sprintf("merged into %s", a.RelatedID)
+	case model.ActivityMergedFrom:
+		detail = fmt.Sprintf("alert %s merged into this", a.RelatedID)
+	case model.ActivityUnmerged:
+		detail = fmt.Sprintf("unmerged from %s", a.RelatedID)
+	case model.ActivityNoteAdded:
+		detail = "note"
+	case model.ActivityChatStarted:
+		detail = fmt.Sprintf("chat started (history: %s)", a.RelatedID)
+	case model.ActivityIncidentAdded:
+		detail = fmt.Sprintf("alert %s added to incident %s", a.AlertID, a.IncidentID)
+	case model.ActivityIncidentRemoved:
+		detail = fmt.Sprintf("alert %s removed from incident %s", a.AlertID, a.IncidentID)
+	default:
+		detail = string(a.Type)
+	}
+
+	fmt.Fprintf(w, "%s\t%s\t%s\n", a.CreatedAt.Format("2006-01-02 15:04:05"), actor, detail)
+	if a.Note != "" {
+		fmt.Fprintf(w, "%s\n", indent(a.Note, "    "))
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/m-mizutani/leveret/pkg/model"
+)
+
+// printUsage prints token usage grouped by caller. Estimated cost is shown
+// only when token prices are configured.
+func printUsage(w io.Writer, cfg *config, usage *model.TokenUsage) {
+	callers := usage.Callers()
+	if len(callers) == 0 {
+		return
+	}
+
+	withCost := cfg.inputTokenPrice > 0 || cfg.outputTokenPrice > 0
+
+	fmt.Fprintf(w, "\nToken usage:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "CALLER\tCALLS\tPROMPT\tRESPONSE\tTHINKING\tTOTAL\t"
+	if withCost {
+		header += "COST(USD)\t"
+	}
+	fmt.Fprintln(tw, header)
+
+	printRow := func(name string, count *model.TokenCount) {
+		row := fmt.Sprintf("%s\t%d\t%d\t%d\t%d\t%d\t", name, count.Calls, count.PromptTokens, count.ResponseTokens, count.ThinkingTokens, count.TotalTokens)
+		if withCost {
+			row += fmt.Sprintf("%.4f\t", estimateCost(cfg, count))
+		}
+		fmt.Fprintln(tw, row)
+	}
+
+	for _, caller := range callers {
+		printRow(caller, usage.ByCaller[caller])
+	}
+	printRow("(total)", usage.Total())
+
+	_ = tw.Flush()
+}
+
+func estimateCost(cfg *config, count *model.TokenCount) float64 {
+	input := float64(count.PromptTokens) * cfg.inputTokenPrice
+	output := float64(count.ResponseTokens+count.ThinkingTokens) * cfg.outputTokenPrice
+	return (input + output) / 1_000_000
+}
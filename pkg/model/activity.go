@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ActivityID string
+
+// NewActivityID generates a new unique ActivityID
+func NewActivityID() ActivityID {
+	return ActivityID(uuid.New().String())
+}
+
+// ActivityType is a kind of event in the timeline of an alert
+type ActivityType string
+
+const (
+	ActivityCreated         ActivityType = "created"          // Alert was saved
+	ActivityTriaged         ActivityType = "triaged"          // To: triage action, Note: triage note
+	ActivityStatusChanged   ActivityType = "status_changed"   // From/To: status
+	ActivityResolved        ActivityType = "resolved"         // From: status, To: conclusion, Note: resolution note
+	ActivityAssigned        ActivityType = "assigned"         // From/To: assignee
+	ActivitySeverityChanged ActivityType = "severity_changed" // From/To: severity
+	ActivityMergedInto      ActivityType = "merged_into"      // RelatedID: target alert
+	ActivityMergedFrom      ActivityType = "merged_from"      // RelatedID: source alert
+	ActivityUnmerged        ActivityType = "unmerged"         // RelatedID: former target alert
+	ActivityNoteAdded       ActivityType = "note_added"       // Note: the note
+	ActivityChatStarted     ActivityType = "chat_started"     // RelatedID: history, Note: title
+	ActivityIncidentAdded   ActivityType = "incident_added"   // Alert was added to the incident
+	ActivityIncidentRemoved ActivityType = "incident_removed" // Alert was removed from the incident
+)
+
+// Activity is an append-only record of what happened to an alert or an
+// incident. An activity having both AlertID and IncidentID, e.g. addition of
+// an alert to an incident, appears in both timelines.
+type Activity struct {
+	ID         ActivityID
+	AlertID    AlertID
+	IncidentID IncidentID
+	Type       ActivityType
+	Actor      string // Who did it. Empty for automated operations
+	CreatedAt  time.Time
+
+	From      string
+	To        string
+	Note      string
+	RelatedID string
+}
+
+// NewActivity creates an activity of the alert happening now
+func NewActivity(alertID AlertID, activityType ActivityType, actor string) *Activity {
+	return &Activity{
+		ID:        NewActivityID(),
+		AlertID:   alertID,
+		Type:      activityType,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewIncidentActivity creates an activity of the incident happening now
+func NewIncidentActivity(incidentID IncidentID, activityType ActivityType, actor string) *Activity {
+	return &Activity{
+		ID:         NewActivityID(),
+		IncidentID: incidentID,
+		Type:       activityType,
+		Actor:      actor,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -1,7 +1,9 @@
 package model
 
 import (
+	"strings"
 	"time"
+	"unicode"
 
 	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
@@ -10,6 +12,8 @@ import (
 
 var (
 	ErrInvalidConclusion = goerr.New("invalid conclusion")
+	ErrInvalidStatus     = goerr.New("invalid alert status")
+	ErrInvalidSeverity   = goerr.New("invalid severity")
 )
 
 type AlertID string
@@ -19,6 +23,12 @@ func NewAlertID() AlertID {
 	return AlertID(uuid.New().String())
 }
 
+// NewAlertIDFromKey generates an AlertID that is always the same for the same
+// key. It is used to make ingestion idempotent, e.g. for redelivered messages.
+func NewAlertIDFromKey(key string) AlertID {
+	return AlertID(uuid.NewSHA1(uuid.NameSpaceURL, []byte("leveret:alert:"+key)).String())
+}
+
 type AttributeType string
 
 const (
@@ -30,6 +40,40 @@ const (
 	AttributeTypeURL       AttributeType = "url"
 )
 
+// AttributeTypes is all attribute types
+var AttributeTypes = []AttributeType{
+	AttributeTypeString,
+	AttributeTypeNumber,
+	AttributeTypeIPAddress,
+	AttributeTypeDomain,
+	AttributeTypeHash,
+	AttributeTypeURL,
+}
+
+// Validate checks if the attribute type is valid
+func (t AttributeType) Validate() error {
+	for _, v := range AttributeTypes {
+		if t == v {
+			return nil
+		}
+	}
+	return goerr.New("invalid attribute type", goerr.V("type", t))
+}
+
+// Normalize returns the value in canonical form of the type so that the same
+// indicator from different sources can be compared, e.g. case of domain.
+func (t AttributeType) Normalize(value string) string {
+	value = strings.TrimSpace(value)
+	switch t {
+	case AttributeTypeIPAddress, AttributeTypeHash:
+		return strings.ToLower(value)
+	case AttributeTypeDomain:
+		return strings.TrimSuffix(strings.ToLower(value), ".")
+	default:
+		return value
+	}
+}
+
 type Conclusion string
 
 const (
@@ -48,6 +92,76 @@ func (c Conclusion) Validate() error {
 	}
 }
 
+// AlertStatus is a lifecycle state of an alert
+type AlertStatus string
+
+const (
+	AlertStatusNew           AlertStatus = "new"
+	AlertStatusAcknowledged  AlertStatus = "acknowledged"
+	AlertStatusInvestigating AlertStatus = "investigating"
+	AlertStatusResolved      AlertStatus = "resolved"
+	AlertStatusClosed        AlertStatus = "closed"
+)
+
+// AlertStatuses is all alert statuses in lifecycle order
+var AlertStatuses = []AlertStatus{
+	AlertStatusNew,
+	AlertStatusAcknowledged,
+	AlertStatusInvestigating,
+	AlertStatusResolved,
+	AlertStatusClosed,
+}
+
+// Validate checks if the status is valid
+func (s AlertStatus) Validate() error {
+	for _, status := range AlertStatuses {
+		if s == status {
+			return nil
+		}
+	}
+	return goerr.Wrap(ErrInvalidStatus, "unknown alert status", goerr.V("status", s))
+}
+
+// Done returns true if no more work is expected on the alert
+func (s AlertStatus) Done() bool {
+	return s == AlertStatusResolved || s == AlertStatusClosed
+}
+
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+	SeverityInfo     Severity = "info"
+)
+
+// Validate checks if the severity is valid
+func (s Severity) Validate() error {
+	switch s {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidSeverity, "unknown severity", goerr.V("severity", s))
+	}
+}
+
+// TriageAction is a decision of triage policy in alert workflow
+type TriageAction string
+
+const (
+	TriageActionAccept  TriageAction = "accept"
+	TriageActionNotify  TriageAction = "notify"
+	TriageActionDiscard TriageAction = "discard"
+)
+
+// Enrichment is an output of an enrichment prompt in alert workflow
+type Enrichment struct {
+	ID     string `json:"id"`
+	Result string `json:"result"`
+}
+
 type Alert struct {
 	ID          AlertID
 	Title       string
@@ -56,11 +170,88 @@ type Alert struct {
 	Attributes  []*Attribute
 	Embedding   firestore.Vector32
 
+	// Results of alert workflow. Empty if the alert was not ingested via workflow.
+	Severity     Severity
+	TriageAction TriageAction
+	TriageNote   string
+	Enrichments  []*Enrichment
+
+	// Lifecycle of the alert. Status is empty for alerts saved before it was
+	// introduced, use CurrentStatus to read it.
+	Status   AlertStatus
+	Assignee string
+
 	CreatedAt  time.Time
 	ResolvedAt *time.Time
 	Conclusion Conclusion
 	Note       string
-	MergedTo   AlertID
+	MergedTo   AlertID // Empty string if not merged, stored as is to be queried
+
+	// Keywords is an index of title words and attribute key/value pairs built
+	// by BuildKeywords. It is refreshed by repository when the alert is saved
+	// so that list filters can be evaluated by database queries.
+	Keywords []string
+}
+
+// TitleKeyword returns the keyword of a word in alert title
+func TitleKeyword(word string) string {
+	return "title:" + strings.ToLower(word)
+}
+
+// AttributeKeyword returns the keyword of an attribute key/value pair
+func AttributeKeyword(key, value string) string {
+	return "attr:" + key + "=" + value
+}
+
+// IOCKeyword returns the keyword of an indicator of the type regardless of
+// attribute key, e.g. src_ip and dst_ip
+func IOCKeyword(t AttributeType, value string) string {
+	return "ioc:" + string(t) + "=" + t.Normalize(value)
+}
+
+// SplitWords splits text into lower-cased words by non letter and digit characters
+func SplitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// BuildKeywords returns keywords of the alert without duplication
+func (a *Alert) BuildKeywords() []string {
+	var keywords []string
+	seen := make(map[string]struct{})
+	add := func(keyword string) {
+		if _, ok := seen[keyword]; ok {
+			return
+		}
+		seen[keyword] = struct{}{}
+		keywords = append(keywords, keyword)
+	}
+
+	for _, word := range SplitWords(a.Title) {
+		add(TitleKeyword(word))
+	}
+	for _, attr := range a.Attributes {
+		if attr == nil {
+			continue
+		}
+		add(AttributeKeyword(attr.Key, attr.Value))
+		add(IOCKeyword(attr.Type, attr.Value))
+	}
+
+	return keywords
+}
+
+// CurrentStatus returns the status of the alert. For alerts without Status,
+// it is derived from ResolvedAt.
+func (a *Alert) CurrentStatus() AlertStatus {
+	if a.Status != "" {
+		return a.Status
+	}
+	if a.ResolvedAt != nil {
+		return AlertStatusResolved
+	}
+	return AlertStatusNew
 }
 
 type Attribute struct {
@@ -77,10 +268,5 @@ func (a *Attribute) Validate() error {
 	if a.Value == "" {
 		return goerr.New("attribute value is empty")
 	}
-	switch a.Type {
-	case AttributeTypeString, AttributeTypeNumber, AttributeTypeIPAddress, AttributeTypeDomain, AttributeTypeHash, AttributeTypeURL:
-		return nil
-	default:
-		return goerr.New("invalid attribute type", goerr.V("type", a.Type))
-	}
+	return a.Type.Validate()
 }
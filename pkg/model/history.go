@@ -14,13 +14,18 @@ func NewHistoryID() HistoryID {
 	return HistoryID(uuid.New().String())
 }
 
-// History represents a conversation history for alert analysis
+// History represents a conversation history for analysis of an alert or an
+// incident. Either AlertID or IncidentID is set.
 type History struct {
-	ID        HistoryID
-	Title     string
-	AlertID   AlertID
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         HistoryID
+	Title      string
+	AlertID    AlertID
+	IncidentID IncidentID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	// Usage is accumulated token usage of LLM calls in this conversation
+	Usage *TokenUsage
 
 	// Do not save history raw data due to size limitation of firestore
 	Contents []*genai.Content `firestore:"-"`
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IncidentID string
+
+// NewIncidentID generates a new unique IncidentID
+func NewIncidentID() IncidentID {
+	return IncidentID(uuid.New().String())
+}
+
+// Incident groups related alerts into a single work item. Unlike MergedTo of
+// an alert, it has its own lifecycle, timeline and conversation histories.
+// An alert belongs to at most one incident.
+type Incident struct {
+	ID          IncidentID
+	Title       string
+	Description string
+	AlertIDs    []AlertID
+
+	// Lifecycle of the incident, same as alerts
+	Status   AlertStatus
+	Assignee string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CurrentStatus returns the status of the incident
+func (x *Incident) CurrentStatus() AlertStatus {
+	if x.Status == "" {
+		return AlertStatusNew
+	}
+	return x.Status
+}
+
+// HasAlert returns true if the alert is a member of the incident
+func (x *Incident) HasAlert(id AlertID) bool {
+	return slices.Contains(x.AlertIDs, id)
+}
@@ -0,0 +1,70 @@
+package model
+
+import "sort"
+
+// TokenCount holds token consumption of LLM calls
+type TokenCount struct {
+	Calls          int64 `json:"calls" yaml:"calls"`
+	PromptTokens   int64 `json:"prompt_tokens" yaml:"prompt_tokens"`
+	ResponseTokens int64 `json:"response_tokens" yaml:"response_tokens"`
+	ThinkingTokens int64 `json:"thinking_tokens" yaml:"thinking_tokens"`
+	TotalTokens    int64 `json:"total_tokens" yaml:"total_tokens"`
+}
+
+// Add accumulates other into c
+func (c *TokenCount) Add(other *TokenCount) {
+	if other == nil {
+		return
+	}
+	c.Calls += other.Calls
+	c.PromptTokens += other.PromptTokens
+	c.ResponseTokens += other.ResponseTokens
+	c.ThinkingTokens += other.ThinkingTokens
+	c.TotalTokens += other.TotalTokens
+}
+
+// TokenUsage holds token consumption grouped by caller (e.g. "summary", "plan", "bigquery_agent")
+type TokenUsage struct {
+	ByCaller map[string]*TokenCount `json:"by_caller" yaml:"by_caller"`
+}
+
+// Merge accumulates other into u
+func (u *TokenUsage) Merge(other *TokenUsage) {
+	if other == nil {
+		return
+	}
+	if u.ByCaller == nil {
+		u.ByCaller = make(map[string]*TokenCount)
+	}
+	for caller, count := range other.ByCaller {
+		if _, ok := u.ByCaller[caller]; !ok {
+			u.ByCaller[caller] = &TokenCount{}
+		}
+		u.ByCaller[caller].Add(count)
+	}
+}
+
+// Total returns the sum of all callers
+func (u *TokenUsage) Total() *TokenCount {
+	total := &TokenCount{}
+	if u == nil {
+		return total
+	}
+	for _, count := range u.ByCaller {
+		total.Add(count)
+	}
+	return total
+}
+
+// Callers returns caller names in sorted order
+func (u *TokenUsage) Callers() []string {
+	if u == nil {
+		return nil
+	}
+	callers := make([]string, 0, len(u.ByCaller))
+	for caller := range u.ByCaller {
+		callers = append(callers, caller)
+	}
+	sort.Strings(callers)
+	return callers
+}
@@ -13,9 +13,11 @@ import (
 )
 
 const (
-	alertCollection   = "alerts"
-	historyCollection = "histories"
-	memoryCollection  = "memories"
+	alertCollection    = "alerts"
+	historyCollection  = "histories"
+	memoryCollection   = "memories"
+	activityCollection = "activities"
+	incidentCollection = "incidents"
 )
 
 // Firestore implements Repository interface using Firestore
@@ -48,12 +50,36 @@ func (r *Firestore) getClient(ctx context.Context) (*firestore.Client, error) {
 	return client, nil
 }
 
+// startAfter sets the cursor of query to the document in the page token.
+// The document is read to get values of the ordered fields.
+func (r *Firestore) startAfter(ctx context.Context, client *firestore.Client, collection string, query firestore.Query, token string) (firestore.Query, error) {
+	if token == "" {
+		return query, nil
+	}
+
+	id, err := decodePageToken(token)
+	if err != nil {
+		return query, err
+	}
+
+	doc, err := client.Collection(collection).Doc(id).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return query, goerr.Wrap(ErrInvalidPageToken, "document of page token not found", goerr.V("id", id))
+		}
+		return query, goerr.Wrap(err, "failed to get document of page token", goerr.V("id", id))
+	}
+
+	return query.StartAfter(doc), nil
+}
+
 func (r *Firestore) PutAlert(ctx context.Context, alert *model.Alert) error {
 	client, err := r.getClient(ctx)
 	if err != nil {
 		return err
 	}
 
+	alert.Keywords = alert.BuildKeywords()
 	_, err = client.Collection(alertCollection).Doc(string(alert.ID)).Set(ctx, alert)
 	if err != nil {
 		return goerr.Wrap(err, "failed to put alert", goerr.Value("id", alert.ID))
@@ -62,6 +88,24 @@ func (r *Firestore) PutAlert(ctx context.Context, alert *model.Alert) error {
 	return nil
 }
 
+func (r *Firestore) CreateAlert(ctx context.Context, alert *model.Alert) error {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	alert.Keywords = alert.BuildKeywords()
+	_, err = client.Collection(alertCollection).Doc(string(alert.ID)).Create(ctx, alert)
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return goerr.Wrap(ErrAlreadyExists, "alert already exists", goerr.Value("id", alert.ID))
+		}
+		return goerr.Wrap(err, "failed to create alert", goerr.Value("id", alert.ID))
+	}
+
+	return nil
+}
+
 func (r *Firestore) GetAlert(ctx context.Context, id model.AlertID) (*model.Alert, error) {
 	client, err := r.getClient(ctx)
 	if err != nil {
@@ -71,7 +115,7 @@ func (r *Firestore) GetAlert(ctx context.Context, id model.AlertID) (*model.Aler
 	doc, err := client.Collection(alertCollection).Doc(string(id)).Get(ctx)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			return nil, goerr.Wrap(err, "alert not found", goerr.Value("id", id))
+			return nil, goerr.Wrap(ErrNotFound, "alert not found", goerr.Value("id", id))
 		}
 		return nil, goerr.Wrap(err, "failed to get alert", goerr.Value("id", id))
 	}
@@ -84,16 +128,74 @@ func (r *Firestore) GetAlert(ctx context.Context, id model.AlertID) (*model.Aler
 	return &alert, nil
 }
 
-func (r *Firestore) ListAlerts(ctx context.Context, offset, limit int) ([]*model.Alert, error) {
+func (r *Firestore) ListAlerts(ctx context.Context, input *ListAlertsInput) ([]*model.Alert, string, error) {
+	if err := input.validate(); err != nil {
+		return nil, "", err
+	}
+
 	client, err := r.getClient(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	// Filters combined with ordering by CreatedAt require composite indexes
+	// defined in firestore.indexes.json. Unset ResolvedAt is stored as null,
+	// and MergedTo of unmerged alerts is stored as an empty string.
+	query := client.Collection(alertCollection).Query
+	switch len(input.Statuses) {
+	case 0:
+	case 1:
+		query = query.Where("Status", "==", input.Statuses[0])
+	default:
+		query = query.Where("Status", "in", input.Statuses)
+	}
+	if input.Assignee != "" {
+		query = query.Where("Assignee", "==", input.Assignee)
+	}
+	if input.Severity != "" {
+		query = query.Where("Severity", "==", input.Severity)
+	}
+	if input.Conclusion != "" {
+		query = query.Where("Conclusion", "==", input.Conclusion)
+	}
+	if input.Resolved != nil {
+		if *input.Resolved {
+			query = query.Where("ResolvedAt", "!=", nil)
+		} else {
+			query = query.Where("ResolvedAt", "==", nil)
+		}
+	}
+	if input.Merged != nil {
+		if *input.Merged {
+			query = query.Where("MergedTo", "!=", "")
+		} else {
+			query = query.Where("MergedTo", "==", "")
+		}
+	}
+	if input.MergedTo != "" {
+		query = query.Where("MergedTo", "==", input.MergedTo)
+	}
+	if !input.CreatedAfter.IsZero() {
+		query = query.Where("CreatedAt", ">=", input.CreatedAfter)
+	}
+	if !input.CreatedBefore.IsZero() {
+		query = query.Where("CreatedAt", "<", input.CreatedBefore)
+	}
+	if keyword := input.keyword(); keyword != "" {
+		query = query.Where("Keywords", "array-contains", keyword)
 	}
 
-	query := client.Collection(alertCollection).
-		OrderBy("CreatedAt", firestore.Desc).
-		Offset(offset).
-		Limit(limit)
+	direction := firestore.Desc
+	if input.Order == SortAsc {
+		direction = firestore.Asc
+	}
+	query = query.OrderBy("CreatedAt", direction).Offset(input.Offset)
+	if input.Limit > 0 {
+		query = query.Limit(input.Limit)
+	}
+	if query, err = r.startAfter(ctx, client, alertCollection, query, input.PageToken); err != nil {
+		return nil, "", err
+	}
 
 	iter := query.Documents(ctx)
 	defer iter.Stop()
@@ -105,23 +207,23 @@ func (r *Firestore) ListAlerts(ctx context.Context, offset, limit int) ([]*model
 			break
 		}
 		if err != nil {
-			return nil, goerr.Wrap(err, "failed to iterate alerts")
+			return nil, "", goerr.Wrap(err, "failed to iterate alerts")
 		}
 
 		var alert model.Alert
 		if err := doc.DataTo(&alert); err != nil {
-			return nil, goerr.Wrap(err, "failed to parse alert data", goerr.Value("id", doc.Ref.ID))
+			return nil, "", goerr.Wrap(err, "failed to parse alert data", goerr.Value("id", doc.Ref.ID))
 		}
 		alerts = append(alerts, &alert)
 	}
 
-	return alerts, nil
+	return alerts, nextPageToken(len(alerts), input.Limit, lastAlertID(alerts)), nil
 }
 
-func (r *Firestore) SearchAlerts(ctx context.Context, input *SearchAlertsInput) ([]*model.Alert, error) {
+func (r *Firestore) SearchAlerts(ctx context.Context, input *SearchAlertsInput) ([]*model.Alert, string, error) {
 	client, err := r.getClient(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Set defaults
@@ -137,10 +239,10 @@ func (r *Firestore) SearchAlerts(ctx context.Context, input *SearchAlertsInput)
 
 	// Validate required fields
 	if input.Field == "" {
-		return nil, goerr.New("field is required")
+		return nil, "", goerr.New("field is required")
 	}
 	if input.Operator == "" {
-		return nil, goerr.New("operator is required")
+		return nil, "", goerr.New("operator is required")
 	}
 
 	// Automatically prefix field path with "Data."
@@ -151,6 +253,9 @@ func (r *Firestore) SearchAlerts(ctx context.Context, input *SearchAlertsInput)
 		Where(fieldPath, input.Operator, input.Value).
 		Offset(input.Offset).
 		Limit(input.Limit)
+	if query, err = r.startAfter(ctx, client, alertCollection, query, input.PageToken); err != nil {
+		return nil, "", err
+	}
 
 	// Execute query
 	iter := query.Documents(ctx)
@@ -163,17 +268,62 @@ func (r *Firestore) SearchAlerts(ctx context.Context, input *SearchAlertsInput)
 			break
 		}
 		if err != nil {
-			return nil, goerr.Wrap(err, "failed to iterate alerts")
+			return nil, "", goerr.Wrap(err, "failed to iterate alerts")
 		}
 
 		var alert model.Alert
 		if err := doc.DataTo(&alert); err != nil {
-			return nil, goerr.Wrap(err, "failed to parse alert data", goerr.Value("id", doc.Ref.ID))
+			return nil, "", goerr.Wrap(err, "failed to parse alert data", goerr.Value("id", doc.Ref.ID))
 		}
 		alerts = append(alerts, &alert)
 	}
 
-	return alerts, nil
+	return alerts, nextPageToken(len(alerts), input.Limit, lastAlertID(alerts)), nil
+}
+
+func (r *Firestore) SearchAlertsByAttribute(ctx context.Context, input *SearchAttributesInput) ([]*model.Alert, string, error) {
+	if err := input.validate(); err != nil {
+		return nil, "", err
+	}
+
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Requires a composite index of Keywords (array) and CreatedAt
+	query := client.Collection(alertCollection).Query
+	if keywords := input.keywords(); len(keywords) == 1 {
+		query = query.Where("Keywords", "array-contains", keywords[0])
+	} else {
+		query = query.Where("Keywords", "array-contains-any", keywords)
+	}
+	query = query.OrderBy("CreatedAt", firestore.Desc).Limit(input.Limit)
+	if query, err = r.startAfter(ctx, client, alertCollection, query, input.PageToken); err != nil {
+		return nil, "", err
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var alerts []*model.Alert
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", goerr.Wrap(err, "failed to iterate alerts")
+		}
+
+		var alert model.Alert
+		if err := doc.DataTo(&alert); err != nil {
+			return nil, "", goerr.Wrap(err, "failed to parse alert data", goerr.Value("id", doc.Ref.ID))
+		}
+		alerts = append(alerts, &alert)
+	}
+
+	return alerts, nextPageToken(len(alerts), input.Limit, lastAlertID(alerts)), nil
 }
 
 func (r *Firestore) SearchSimilarAlerts(ctx context.Context, embedding []float32, threshold float64) ([]*model.Alert, error) {
@@ -238,7 +388,7 @@ func (r *Firestore) GetHistory(ctx context.Context, id model.HistoryID) (*model.
 	doc, err := client.Collection(historyCollection).Doc(string(id)).Get(ctx)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			return nil, goerr.Wrap(err, "history not found", goerr.Value("id", id))
+			return nil, goerr.Wrap(ErrNotFound, "history not found", goerr.Value("id", id))
 		}
 		return nil, goerr.Wrap(err, "failed to get history", goerr.Value("id", id))
 	}
@@ -251,16 +401,57 @@ func (r *Firestore) GetHistory(ctx context.Context, id model.HistoryID) (*model.
 	return &history, nil
 }
 
-func (r *Firestore) ListHistory(ctx context.Context, offset, limit int) ([]*model.History, error) {
+func (r *Firestore) ListHistory(ctx context.Context, input *ListHistoryInput) ([]*model.History, string, error) {
 	client, err := r.getClient(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	query := client.Collection(historyCollection).
 		OrderBy("CreatedAt", firestore.Desc).
-		Offset(offset).
-		Limit(limit)
+		Offset(input.Offset)
+	if input.Limit > 0 {
+		query = query.Limit(input.Limit)
+	}
+	if query, err = r.startAfter(ctx, client, historyCollection, query, input.PageToken); err != nil {
+		return nil, "", err
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var histories []*model.History
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", goerr.Wrap(err, "failed to iterate histories")
+		}
+
+		var history model.History
+		if err := doc.DataTo(&history); err != nil {
+			return nil, "", goerr.Wrap(err, "failed to parse history data", goerr.Value("id", doc.Ref.ID))
+		}
+		histories = append(histories, &history)
+	}
+
+	var lastID string
+	if len(histories) > 0 {
+		lastID = string(histories[len(histories)-1].ID)
+	}
+	return histories, nextPageToken(len(histories), input.Limit, lastID), nil
+}
+
+func (r *Firestore) ListHistoryByAlert(ctx context.Context, alertID model.AlertID) ([]*model.History, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := client.Collection(historyCollection).
+		Where("AlertID", "==", alertID)
 
 	iter := query.Documents(ctx)
 	defer iter.Stop()
@@ -282,17 +473,22 @@ func (r *Firestore) ListHistory(ctx context.Context, offset, limit int) ([]*mode
 		histories = append(histories, &history)
 	}
 
+	// Sort in-memory by CreatedAt descending
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].CreatedAt.After(histories[j].CreatedAt)
+	})
+
 	return histories, nil
 }
 
-func (r *Firestore) ListHistoryByAlert(ctx context.Context, alertID model.AlertID) ([]*model.History, error) {
+func (r *Firestore) ListHistoryByIncident(ctx context.Context, incidentID model.IncidentID) ([]*model.History, error) {
 	client, err := r.getClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	query := client.Collection(historyCollection).
-		Where("AlertID", "==", alertID)
+		Where("IncidentID", "==", incidentID)
 
 	iter := query.Documents(ctx)
 	defer iter.Stop()
@@ -322,6 +518,208 @@ func (r *Firestore) ListHistoryByAlert(ctx context.Context, alertID model.AlertI
 	return histories, nil
 }
 
+func (r *Firestore) AddActivity(ctx context.Context, activity *model.Activity) error {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Create fails if the document exists, which keeps the timeline append-only
+	_, err = client.Collection(activityCollection).Doc(string(activity.ID)).Create(ctx, activity)
+	if err != nil {
+		return goerr.Wrap(err, "failed to add activity", goerr.Value("id", activity.ID), goerr.Value("alertID", activity.AlertID))
+	}
+
+	return nil
+}
+
+func (r *Firestore) ListActivitiesByAlert(ctx context.Context, alertID model.AlertID) ([]*model.Activity, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := client.Collection(activityCollection).
+		Where("AlertID", "==", alertID)
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var activities []*model.Activity
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate activities")
+		}
+
+		var activity model.Activity
+		if err := doc.DataTo(&activity); err != nil {
+			return nil, goerr.Wrap(err, "failed to parse activity data", goerr.Value("id", doc.Ref.ID))
+		}
+		activities = append(activities, &activity)
+	}
+
+	// Sort in-memory by CreatedAt ascending
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].CreatedAt.Before(activities[j].CreatedAt)
+	})
+
+	return activities, nil
+}
+
+func (r *Firestore) ListActivitiesByIncident(ctx context.Context, incidentID model.IncidentID) ([]*model.Activity, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := client.Collection(activityCollection).
+		Where("IncidentID", "==", incidentID)
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var activities []*model.Activity
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate activities")
+		}
+
+		var activity model.Activity
+		if err := doc.DataTo(&activity); err != nil {
+			return nil, goerr.Wrap(err, "failed to parse activity data", goerr.Value("id", doc.Ref.ID))
+		}
+		activities = append(activities, &activity)
+	}
+
+	// Sort in-memory by CreatedAt ascending
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].CreatedAt.Before(activities[j].CreatedAt)
+	})
+
+	return activities, nil
+}
+
+func (r *Firestore) PutIncident(ctx context.Context, incident *model.Incident) error {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Collection(incidentCollection).Doc(string(incident.ID)).Set(ctx, incident)
+	if err != nil {
+		return goerr.Wrap(err, "failed to put incident", goerr.Value("id", incident.ID))
+	}
+
+	return nil
+}
+
+func (r *Firestore) GetIncident(ctx context.Context, id model.IncidentID) (*model.Incident, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := client.Collection(incidentCollection).Doc(string(id)).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(ErrNotFound, "incident not found", goerr.Value("id", id))
+		}
+		return nil, goerr.Wrap(err, "failed to get incident", goerr.Value("id", id))
+	}
+
+	var incident model.Incident
+	if err := doc.DataTo(&incident); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse incident data", goerr.Value("id", id))
+	}
+
+	return &incident, nil
+}
+
+func (r *Firestore) ListIncidents(ctx context.Context, input *ListIncidentsInput) ([]*model.Incident, string, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := client.Collection(incidentCollection).Query
+	if input.Status != nil {
+		query = query.Where("Status", "==", *input.Status)
+	}
+	query = query.OrderBy("CreatedAt", firestore.Desc)
+	if input.Limit > 0 {
+		query = query.Limit(input.Limit)
+	}
+	if query, err = r.startAfter(ctx, client, incidentCollection, query, input.PageToken); err != nil {
+		return nil, "", err
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var incidents []*model.Incident
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", goerr.Wrap(err, "failed to iterate incidents")
+		}
+
+		var incident model.Incident
+		if err := doc.DataTo(&incident); err != nil {
+			return nil, "", goerr.Wrap(err, "failed to parse incident data", goerr.Value("id", doc.Ref.ID))
+		}
+		incidents = append(incidents, &incident)
+	}
+
+	var lastID string
+	if len(incidents) > 0 {
+		lastID = string(incidents[len(incidents)-1].ID)
+	}
+	return incidents, nextPageToken(len(incidents), input.Limit, lastID), nil
+}
+
+func (r *Firestore) ListIncidentsByAlert(ctx context.Context, alertID model.AlertID) ([]*model.Incident, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := client.Collection(incidentCollection).
+		Where("AlertIDs", "array-contains", alertID)
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var incidents []*model.Incident
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate incidents")
+		}
+
+		var incident model.Incident
+		if err := doc.DataTo(&incident); err != nil {
+			return nil, goerr.Wrap(err, "failed to parse incident data", goerr.Value("id", doc.Ref.ID))
+		}
+		incidents = append(incidents, &incident)
+	}
+
+	return incidents, nil
+}
+
 func (r *Firestore) PutMemory(ctx context.Context, memory *model.Memory) error {
 	client, err := r.getClient(ctx)
 	if err != nil {
@@ -345,7 +743,7 @@ func (r *Firestore) GetMemory(ctx context.Context, id model.MemoryID) (*model.Me
 	doc, err := client.Collection(memoryCollection).Doc(string(id)).Get(ctx)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			return nil, goerr.Wrap(err, "memory not found", goerr.V("id", id))
+			return nil, goerr.Wrap(ErrNotFound, "memory not found", goerr.V("id", id))
 		}
 		return nil, goerr.Wrap(err, "failed to get memory", goerr.V("id", id))
 	}
@@ -407,7 +805,7 @@ func (r *Firestore) UpdateMemoryScore(ctx context.Context, id model.MemoryID, de
 		doc, err := tx.Get(docRef)
 		if err != nil {
 			if status.Code(err) == codes.NotFound {
-				return goerr.Wrap(err, "memory not found", goerr.V("id", id))
+				return goerr.Wrap(ErrNotFound, "memory not found", goerr.V("id", id))
 			}
 			return goerr.Wrap(err, "failed to get memory in transaction", goerr.V("id", id))
 		}
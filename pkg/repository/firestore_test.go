@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
 	"os"
 	"testing"
@@ -124,7 +125,7 @@ func TestFirestoreListAlerts(t *testing.T) {
 	}
 
 	// List alerts with limit - just verify we got results and they're ordered
-	retrieved, err := repo.ListAlerts(ctx, 0, 10)
+	retrieved, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 10})
 	gt.NoError(t, err)
 	gt.A(t, retrieved).Longer(2)
 
@@ -139,12 +140,121 @@ func TestFirestoreListAlerts(t *testing.T) {
 	}
 }
 
+// TestFirestoreListAlertsFilters runs filters as Firestore queries. It
+// requires composite indexes in firestore.indexes.json to be deployed. A
+// random title word scopes alerts to this run as the database is shared.
+func TestFirestoreListAlertsFilters(t *testing.T) {
+	repo := setupFirestore(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	rng := rand.New(rand.NewSource(now.UnixNano()))
+	word := fmt.Sprintf("leveret%d", rng.Int63())
+
+	alerts := []*model.Alert{
+		{
+			ID:     model.NewAlertID(),
+			Title:  "Filter " + word,
+			Status: model.AlertStatusNew,
+			Attributes: []*model.Attribute{
+				{Key: "user", Value: word, Type: model.AttributeTypeString},
+			},
+			CreatedAt: now.Add(-3 * time.Minute),
+		},
+		{
+			ID:        model.NewAlertID(),
+			Title:     "Filter " + word,
+			Status:    model.AlertStatusInvestigating,
+			Assignee:  "alice",
+			CreatedAt: now.Add(-2 * time.Minute),
+		},
+		{
+			ID:        model.NewAlertID(),
+			Title:     "Filter " + word,
+			Status:    model.AlertStatusNew,
+			CreatedAt: now.Add(-time.Minute),
+		},
+	}
+	alerts[2].MergedTo = alerts[0].ID
+	for _, a := range alerts {
+		gt.NoError(t, repo.PutAlert(ctx, a))
+	}
+
+	ids := func(alerts []*model.Alert) []model.AlertID {
+		var ids []model.AlertID
+		for _, a := range alerts {
+			ids = append(ids, a.ID)
+		}
+		return ids
+	}
+	merged, notMerged := true, false
+
+	testCases := []struct {
+		name  string
+		input *repository.ListAlertsInput
+		want  []model.AlertID
+	}{
+		{
+			name:  "keyword",
+			input: &repository.ListAlertsInput{TitleKeyword: word},
+			want:  []model.AlertID{alerts[2].ID, alerts[1].ID, alerts[0].ID},
+		},
+		{
+			name:  "keyword ascending",
+			input: &repository.ListAlertsInput{TitleKeyword: word, Order: repository.SortAsc},
+			want:  []model.AlertID{alerts[0].ID, alerts[1].ID, alerts[2].ID},
+		},
+		{
+			name:  "attribute",
+			input: &repository.ListAlertsInput{AttributeKey: "user", AttributeValue: word},
+			want:  []model.AlertID{alerts[0].ID},
+		},
+		{
+			name:  "not merged",
+			input: &repository.ListAlertsInput{TitleKeyword: word, Merged: &notMerged},
+			want:  []model.AlertID{alerts[1].ID, alerts[0].ID},
+		},
+		{
+			name:  "merged",
+			input: &repository.ListAlertsInput{TitleKeyword: word, Merged: &merged},
+			want:  []model.AlertID{alerts[2].ID},
+		},
+		{
+			name:  "merged to",
+			input: &repository.ListAlertsInput{MergedTo: alerts[0].ID},
+			want:  []model.AlertID{alerts[2].ID},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _, err := repo.ListAlerts(ctx, tc.input)
+			gt.NoError(t, err)
+			gt.Equal(t, ids(got), tc.want)
+		})
+	}
+
+	t.Run("page token", func(t *testing.T) {
+		input := &repository.ListAlertsInput{TitleKeyword: word, Limit: 2}
+		first, token, err := repo.ListAlerts(ctx, input)
+		gt.NoError(t, err)
+		gt.Equal(t, ids(first), []model.AlertID{alerts[2].ID, alerts[1].ID})
+		gt.NotEqual(t, token, "")
+
+		input.PageToken = token
+		second, token, err := repo.ListAlerts(ctx, input)
+		gt.NoError(t, err)
+		gt.Equal(t, ids(second), []model.AlertID{alerts[0].ID})
+		gt.Equal(t, token, "")
+	})
+}
+
 func TestFirestoreListAlertsEmpty(t *testing.T) {
 	repo := setupFirestore(t)
 	ctx := context.Background()
 
 	// List with large offset should return empty
-	retrieved, err := repo.ListAlerts(ctx, 10000, 10)
+	retrieved, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Offset: 10000, Limit: 10})
 	gt.NoError(t, err)
 	gt.A(t, retrieved).Length(0)
 }
@@ -242,7 +352,7 @@ func TestFirestoreListHistory(t *testing.T) {
 	}
 
 	// List histories with limit - just verify we got results and they're ordered
-	retrieved, err := repo.ListHistory(ctx, 0, 10)
+	retrieved, _, err := repo.ListHistory(ctx, &repository.ListHistoryInput{Limit: 10})
 	gt.NoError(t, err)
 	gt.A(t, retrieved).Longer(2)
 
@@ -262,7 +372,7 @@ func TestFirestoreListHistoryEmpty(t *testing.T) {
 	ctx := context.Background()
 
 	// List with large offset should return empty
-	retrieved, err := repo.ListHistory(ctx, 10000, 10)
+	retrieved, _, err := repo.ListHistory(ctx, &repository.ListHistoryInput{Offset: 10000, Limit: 10})
 	gt.NoError(t, err)
 	gt.A(t, retrieved).Length(0)
 }
@@ -321,7 +431,7 @@ func TestFirestoreSearchAlerts(t *testing.T) {
 	time.Sleep(2 * time.Second)
 
 	t.Run("search by severity string equality", func(t *testing.T) {
-		results, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		results, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "severity",
 			Operator: "==",
 			Value:    "critical",
@@ -339,7 +449,7 @@ func TestFirestoreSearchAlerts(t *testing.T) {
 	})
 
 	t.Run("search by source", func(t *testing.T) {
-		results, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		results, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "source",
 			Operator: "==",
 			Value:    "guardduty",
@@ -357,7 +467,7 @@ func TestFirestoreSearchAlerts(t *testing.T) {
 	})
 
 	t.Run("search by numeric score greater than", func(t *testing.T) {
-		results, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		results, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "score",
 			Operator: ">",
 			Value:    6,
@@ -386,7 +496,7 @@ func TestFirestoreSearchAlerts(t *testing.T) {
 	})
 
 	t.Run("search with limit", func(t *testing.T) {
-		results, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		results, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "source",
 			Operator: "==",
 			Value:    "guardduty",
@@ -400,7 +510,7 @@ func TestFirestoreSearchAlerts(t *testing.T) {
 	})
 
 	t.Run("search with no results", func(t *testing.T) {
-		results, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		results, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "severity",
 			Operator: "==",
 			Value:    "nonexistent",
@@ -417,7 +527,7 @@ func TestFirestoreSearchAlertsValidation(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("missing field", func(t *testing.T) {
-		_, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		_, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "",
 			Operator: "==",
 			Value:    "test",
@@ -426,7 +536,7 @@ func TestFirestoreSearchAlertsValidation(t *testing.T) {
 	})
 
 	t.Run("missing operator", func(t *testing.T) {
-		_, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		_, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "severity",
 			Operator: "",
 			Value:    "test",
@@ -436,7 +546,7 @@ func TestFirestoreSearchAlertsValidation(t *testing.T) {
 
 	t.Run("default limit", func(t *testing.T) {
 		// Should not error with limit=0 (uses default)
-		_, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		_, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "severity",
 			Operator: "==",
 			Value:    "test",
@@ -447,7 +557,7 @@ func TestFirestoreSearchAlertsValidation(t *testing.T) {
 
 	t.Run("limit exceeds maximum", func(t *testing.T) {
 		// Should cap at 100
-		_, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		_, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
 			Field:    "severity",
 			Operator: "==",
 			Value:    "test",
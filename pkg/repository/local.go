@@ -0,0 +1,1046 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+)
+
+// Local implements Repository interface using JSON files in a local directory.
+// Each document is stored as <dir>/<collection>/<id>.json so that the data can
+// be inspected and edited by hand. Queries are evaluated by scanning all
+// documents in the collection, which is fine for laptop-sized datasets.
+type Local struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+var _ Repository = &Local{}
+
+// NewLocal creates a new Local repository rooted at dir
+func NewLocal(dir string) (*Local, error) {
+	if dir == "" {
+		return nil, goerr.New("local repository directory is required")
+	}
+
+	for _, collection := range []string{alertCollection, historyCollection, memoryCollection, activityCollection, incidentCollection} {
+		path := filepath.Join(dir, collection)
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return nil, goerr.Wrap(err, "failed to create local repository directory", goerr.V("path", path))
+		}
+	}
+
+	return &Local{dir: dir}, nil
+}
+
+func (r *Local) docPath(collection, id string) string {
+	return filepath.Join(r.dir, collection, id+".json")
+}
+
+func (r *Local) putDoc(collection, id string, v any) error {
+	return r.writeDoc(collection, id, v, false)
+}
+
+// createDoc writes a new document. It fails with ErrAlreadyExists if the
+// document exists, also against other processes sharing the directory.
+func (r *Local) createDoc(collection, id string, v any) error {
+	return r.writeDoc(collection, id, v, true)
+}
+
+func (r *Local) writeDoc(collection, id string, v any, create bool) error {
+	if id == "" {
+		return goerr.New("document ID is empty", goerr.V("collection", collection))
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal document", goerr.V("collection", collection), goerr.V("id", id))
+	}
+
+	// Write to a temporary file first and rename it to avoid partially written documents
+	path := r.docPath(collection, id)
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+id+".*.tmp")
+	if err != nil {
+		return goerr.Wrap(err, "failed to create temporary file", goerr.V("path", path))
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return goerr.Wrap(err, "failed to write document", goerr.V("path", path))
+	}
+	if err := tmp.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close temporary file", goerr.V("path", path))
+	}
+	if create {
+		// Hard link fails if the path exists, unlike rename
+		if err := os.Link(tmp.Name(), path); err != nil {
+			if errors.Is(err, fs.ErrExist) {
+				return goerr.Wrap(ErrAlreadyExists, "document already exists", goerr.V("collection", collection), goerr.V("id", id))
+			}
+			return goerr.Wrap(err, "failed to link document", goerr.V("path", path))
+		}
+		return nil
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return goerr.Wrap(err, "failed to rename document", goerr.V("path", path))
+	}
+
+	return nil
+}
+
+// getDoc reads a document into v. It returns false if the document does not exist.
+func (r *Local) getDoc(collection, id string, v any) (bool, error) {
+	data, err := os.ReadFile(r.docPath(collection, id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, goerr.Wrap(err, "failed to read document", goerr.V("collection", collection), goerr.V("id", id))
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, goerr.Wrap(err, "failed to parse document", goerr.V("collection", collection), goerr.V("id", id))
+	}
+
+	return true, nil
+}
+
+// listDocIDs returns IDs of all documents in the collection
+func (r *Local) listDocIDs(collection string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(r.dir, collection))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read collection directory", goerr.V("collection", collection))
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+
+	return ids, nil
+}
+
+func (r *Local) allAlerts() ([]*model.Alert, error) {
+	ids, err := r.listDocIDs(alertCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]*model.Alert, 0, len(ids))
+	for _, id := range ids {
+		var alert model.Alert
+		found, err := r.getDoc(alertCollection, id, &alert)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			alerts = append(alerts, &alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+func (r *Local) allHistories() ([]*model.History, error) {
+	ids, err := r.listDocIDs(historyCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	histories := make([]*model.History, 0, len(ids))
+	for _, id := range ids {
+		var history model.History
+		found, err := r.getDoc(historyCollection, id, &history)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			histories = append(histories, &history)
+		}
+	}
+
+	return histories, nil
+}
+
+func (r *Local) allIncidents() ([]*model.Incident, error) {
+	ids, err := r.listDocIDs(incidentCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	incidents := make([]*model.Incident, 0, len(ids))
+	for _, id := range ids {
+		var incident model.Incident
+		found, err := r.getDoc(incidentCollection, id, &incident)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			incidents = append(incidents, &incident)
+		}
+	}
+	return incidents, nil
+}
+
+func (r *Local) allMemories() ([]*model.Memory, error) {
+	ids, err := r.listDocIDs(memoryCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	memories := make([]*model.Memory, 0, len(ids))
+	for _, id := range ids {
+		var memory model.Memory
+		found, err := r.getDoc(memoryCollection, id, &memory)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			memories = append(memories, &memory)
+		}
+	}
+
+	return memories, nil
+}
+
+func (r *Local) PutAlert(ctx context.Context, alert *model.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alert.Keywords = alert.BuildKeywords()
+	if err := r.putDoc(alertCollection, string(alert.ID), alert); err != nil {
+		return goerr.Wrap(err, "failed to put alert", goerr.Value("id", alert.ID))
+	}
+
+	return nil
+}
+
+func (r *Local) CreateAlert(ctx context.Context, alert *model.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alert.Keywords = alert.BuildKeywords()
+	if err := r.createDoc(alertCollection, string(alert.ID), alert); err != nil {
+		return goerr.Wrap(err, "failed to create alert", goerr.Value("id", alert.ID))
+	}
+
+	return nil
+}
+
+func (r *Local) GetAlert(ctx context.Context, id model.AlertID) (*model.Alert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var alert model.Alert
+	found, err := r.getDoc(alertCollection, string(id), &alert)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert", goerr.Value("id", id))
+	}
+	if !found {
+		return nil, goerr.Wrap(ErrNotFound, "alert not found", goerr.Value("id", id))
+	}
+
+	return &alert, nil
+}
+
+func (r *Local) ListAlerts(ctx context.Context, input *ListAlertsInput) ([]*model.Alert, string, error) {
+	if err := input.validate(); err != nil {
+		return nil, "", err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all, err := r.allAlerts()
+	if err != nil {
+		return nil, "", goerr.Wrap(err, "failed to list alerts")
+	}
+
+	alerts := make([]*model.Alert, 0, len(all))
+	for _, alert := range all {
+		if input.match(alert) {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	// Ties of CreatedAt are ordered by ID so that page token works as expected
+	less := func(a, b *model.Alert) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			if input.Order == SortAsc {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return less(alerts[i], alerts[j])
+	})
+
+	if input.PageToken != "" {
+		var cursor model.Alert
+		if err := r.getCursor(alertCollection, input.PageToken, &cursor); err != nil {
+			return nil, "", err
+		}
+		alerts = startAfter(alerts, &cursor, less)
+	}
+
+	alerts = paginate(alerts, input.Offset, input.Limit)
+	return alerts, nextPageToken(len(alerts), input.Limit, lastAlertID(alerts)), nil
+}
+
+func (r *Local) SearchAlerts(ctx context.Context, input *SearchAlertsInput) ([]*model.Alert, string, error) {
+	// Set defaults
+	if input.Limit <= 0 {
+		input.Limit = 10
+	}
+	if input.Limit > 100 {
+		input.Limit = 100
+	}
+	if input.Offset < 0 {
+		input.Offset = 0
+	}
+
+	// Validate required fields
+	if input.Field == "" {
+		return nil, "", goerr.New("field is required")
+	}
+	if input.Operator == "" {
+		return nil, "", goerr.New("operator is required")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alerts, err := r.allAlerts()
+	if err != nil {
+		return nil, "", goerr.Wrap(err, "failed to search alerts")
+	}
+
+	// Keep the result order stable across calls so that offset and page token
+	// work as expected
+	less := func(a, b *model.Alert) bool {
+		return a.ID < b.ID
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return less(alerts[i], alerts[j])
+	})
+
+	if input.PageToken != "" {
+		var cursor model.Alert
+		if err := r.getCursor(alertCollection, input.PageToken, &cursor); err != nil {
+			return nil, "", err
+		}
+		alerts = startAfter(alerts, &cursor, less)
+	}
+
+	path := strings.Split(input.Field, ".")
+	var matched []*model.Alert
+	for _, alert := range alerts {
+		fieldValue, ok := lookupField(alert.Data, path)
+		if !ok {
+			continue
+		}
+
+		match, err := evalOperator(input.Operator, fieldValue, input.Value)
+		if err != nil {
+			return nil, "", err
+		}
+		if match {
+			matched = append(matched, alert)
+		}
+	}
+
+	matched = paginate(matched, input.Offset, input.Limit)
+	return matched, nextPageToken(len(matched), input.Limit, lastAlertID(matched)), nil
+}
+
+func (r *Local) SearchAlertsByAttribute(ctx context.Context, input *SearchAttributesInput) ([]*model.Alert, string, error) {
+	if err := input.validate(); err != nil {
+		return nil, "", err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all, err := r.allAlerts()
+	if err != nil {
+		return nil, "", goerr.Wrap(err, "failed to search alerts by attribute")
+	}
+
+	keywords := input.keywords()
+	var alerts []*model.Alert
+	for _, alert := range all {
+		if slices.ContainsFunc(keywords, func(keyword string) bool {
+			return slices.Contains(alert.Keywords, keyword)
+		}) {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	less := func(a, b *model.Alert) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return less(alerts[i], alerts[j])
+	})
+
+	if input.PageToken != "" {
+		var cursor model.Alert
+		if err := r.getCursor(alertCollection, input.PageToken, &cursor); err != nil {
+			return nil, "", err
+		}
+		alerts = startAfter(alerts, &cursor, less)
+	}
+
+	alerts = paginate(alerts, 0, input.Limit)
+	return alerts, nextPageToken(len(alerts), input.Limit, lastAlertID(alerts)), nil
+}
+
+func (r *Local) SearchSimilarAlerts(ctx context.Context, embedding []float32, threshold float64) ([]*model.Alert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alerts, err := r.allAlerts()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to search similar alerts")
+	}
+
+	type scored struct {
+		alert    *model.Alert
+		distance float64
+	}
+
+	var candidates []scored
+	for _, alert := range alerts {
+		if len(alert.Embedding) == 0 {
+			continue
+		}
+		distance, ok := CosineDistance(embedding, alert.Embedding)
+		if !ok || distance > threshold {
+			continue
+		}
+		candidates = append(candidates, scored{alert: alert, distance: distance})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	// Same upper bound as the Firestore FindNearest query
+	const maxResults = 1000
+	if len(candidates) > maxResults {
+		candidates = candidates[:maxResults]
+	}
+
+	result := make([]*model.Alert, 0, len(candidates))
+	for _, c := range candidates {
+		result = append(result, c.alert)
+	}
+
+	return result, nil
+}
+
+func (r *Local) PutHistory(ctx context.Context, history *model.History) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Contents are saved to Storage, same as the Firestore implementation
+	doc := *history
+	doc.Contents = nil
+
+	if err := r.putDoc(historyCollection, string(history.ID), &doc); err != nil {
+		return goerr.Wrap(err, "failed to put history", goerr.Value("id", history.ID))
+	}
+
+	return nil
+}
+
+func (r *Local) GetHistory(ctx context.Context, id model.HistoryID) (*model.History, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var history model.History
+	found, err := r.getDoc(historyCollection, string(id), &history)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get history", goerr.Value("id", id))
+	}
+	if !found {
+		return nil, goerr.Wrap(ErrNotFound, "history not found", goerr.Value("id", id))
+	}
+
+	return &history, nil
+}
+
+func (r *Local) ListHistory(ctx context.Context, input *ListHistoryInput) ([]*model.History, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	histories, err := r.allHistories()
+	if err != nil {
+		return nil, "", goerr.Wrap(err, "failed to list histories")
+	}
+
+	less := func(a, b *model.History) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		return less(histories[i], histories[j])
+	})
+
+	if input.PageToken != "" {
+		var cursor model.History
+		if err := r.getCursor(historyCollection, input.PageToken, &cursor); err != nil {
+			return nil, "", err
+		}
+		histories = startAfter(histories, &cursor, less)
+	}
+
+	histories = paginate(histories, input.Offset, input.Limit)
+
+	var lastID string
+	if len(histories) > 0 {
+		lastID = string(histories[len(histories)-1].ID)
+	}
+	return histories, nextPageToken(len(histories), input.Limit, lastID), nil
+}
+
+func (r *Local) ListHistoryByAlert(ctx context.Context, alertID model.AlertID) ([]*model.History, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all, err := r.allHistories()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list histories")
+	}
+
+	var histories []*model.History
+	for _, history := range all {
+		if history.AlertID == alertID {
+			histories = append(histories, history)
+		}
+	}
+
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].CreatedAt.After(histories[j].CreatedAt)
+	})
+
+	return histories, nil
+}
+
+func (r *Local) ListHistoryByIncident(ctx context.Context, incidentID model.IncidentID) ([]*model.History, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all, err := r.allHistories()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list histories")
+	}
+
+	var histories []*model.History
+	for _, history := range all {
+		if history.IncidentID == incidentID {
+			histories = append(histories, history)
+		}
+	}
+
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].CreatedAt.After(histories[j].CreatedAt)
+	})
+
+	return histories, nil
+}
+
+func (r *Local) AddActivity(ctx context.Context, activity *model.Activity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var existing model.Activity
+	found, err := r.getDoc(activityCollection, string(activity.ID), &existing)
+	if err != nil {
+		return goerr.Wrap(err, "failed to check activity", goerr.Value("id", activity.ID))
+	}
+	if found {
+		return goerr.New("activity already exists", goerr.Value("id", activity.ID))
+	}
+
+	if err := r.putDoc(activityCollection, string(activity.ID), activity); err != nil {
+		return goerr.Wrap(err, "failed to add activity", goerr.Value("id", activity.ID), goerr.Value("alertID", activity.AlertID))
+	}
+
+	return nil
+}
+
+func (r *Local) ListActivitiesByAlert(ctx context.Context, alertID model.AlertID) ([]*model.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filterActivities(func(a *model.Activity) bool {
+		return a.AlertID == alertID
+	})
+}
+
+func (r *Local) ListActivitiesByIncident(ctx context.Context, incidentID model.IncidentID) ([]*model.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filterActivities(func(a *model.Activity) bool {
+		return a.IncidentID == incidentID
+	})
+}
+
+// filterActivities returns activities matching f in ascending order of time
+func (r *Local) filterActivities(f func(*model.Activity) bool) ([]*model.Activity, error) {
+	ids, err := r.listDocIDs(activityCollection)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list activities")
+	}
+
+	var activities []*model.Activity
+	for _, id := range ids {
+		var activity model.Activity
+		found, err := r.getDoc(activityCollection, id, &activity)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list activities")
+		}
+		if found && f(&activity) {
+			activities = append(activities, &activity)
+		}
+	}
+
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].CreatedAt.Before(activities[j].CreatedAt)
+	})
+
+	return activities, nil
+}
+
+func (r *Local) PutIncident(ctx context.Context, incident *model.Incident) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.putDoc(incidentCollection, string(incident.ID), incident); err != nil {
+		return goerr.Wrap(err, "failed to put incident", goerr.Value("id", incident.ID))
+	}
+
+	return nil
+}
+
+func (r *Local) GetIncident(ctx context.Context, id model.IncidentID) (*model.Incident, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var incident model.Incident
+	found, err := r.getDoc(incidentCollection, string(id), &incident)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get incident", goerr.Value("id", id))
+	}
+	if !found {
+		return nil, goerr.Wrap(ErrNotFound, "incident not found", goerr.Value("id", id))
+	}
+
+	return &incident, nil
+}
+
+func (r *Local) ListIncidents(ctx context.Context, input *ListIncidentsInput) ([]*model.Incident, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all, err := r.allIncidents()
+	if err != nil {
+		return nil, "", goerr.Wrap(err, "failed to list incidents")
+	}
+
+	var incidents []*model.Incident
+	for _, incident := range all {
+		if input.Status != nil && incident.Status != *input.Status {
+			continue
+		}
+		incidents = append(incidents, incident)
+	}
+
+	less := func(a, b *model.Incident) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(incidents, func(i, j int) bool {
+		return less(incidents[i], incidents[j])
+	})
+
+	if input.PageToken != "" {
+		var cursor model.Incident
+		if err := r.getCursor(incidentCollection, input.PageToken, &cursor); err != nil {
+			return nil, "", err
+		}
+		incidents = startAfter(incidents, &cursor, less)
+	}
+
+	incidents = paginate(incidents, 0, input.Limit)
+
+	var lastID string
+	if len(incidents) > 0 {
+		lastID = string(incidents[len(incidents)-1].ID)
+	}
+	return incidents, nextPageToken(len(incidents), input.Limit, lastID), nil
+}
+
+func (r *Local) ListIncidentsByAlert(ctx context.Context, alertID model.AlertID) ([]*model.Incident, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all, err := r.allIncidents()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list incidents")
+	}
+
+	var incidents []*model.Incident
+	for _, incident := range all {
+		if incident.HasAlert(alertID) {
+			incidents = append(incidents, incident)
+		}
+	}
+
+	return incidents, nil
+}
+
+func (r *Local) PutMemory(ctx context.Context, memory *model.Memory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.putDoc(memoryCollection, string(memory.ID), memory); err != nil {
+		return goerr.Wrap(err, "failed to put memory", goerr.V("id", memory.ID))
+	}
+
+	return nil
+}
+
+func (r *Local) GetMemory(ctx context.Context, id model.MemoryID) (*model.Memory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var memory model.Memory
+	found, err := r.getDoc(memoryCollection, string(id), &memory)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get memory", goerr.V("id", id))
+	}
+	if !found {
+		return nil, goerr.Wrap(ErrNotFound, "memory not found", goerr.V("id", id))
+	}
+
+	return &memory, nil
+}
+
+func (r *Local) SearchMemories(ctx context.Context, embedding firestore.Vector32, threshold float64, limit int) ([]*model.Memory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	memories, err := r.allMemories()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to search similar memories")
+	}
+
+	type scored struct {
+		memory   *model.Memory
+		distance float64
+	}
+
+	var candidates []scored
+	for _, memory := range memories {
+		if len(memory.Embedding) == 0 {
+			continue
+		}
+		distance, ok := CosineDistance(embedding, memory.Embedding)
+		if !ok || distance > threshold {
+			continue
+		}
+		candidates = append(candidates, scored{memory: memory, distance: distance})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	result := make([]*model.Memory, 0, len(candidates))
+	for _, c := range candidates {
+		result = append(result, c.memory)
+	}
+
+	return result, nil
+}
+
+func (r *Local) UpdateMemoryScore(ctx context.Context, id model.MemoryID, delta float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var memory model.Memory
+	found, err := r.getDoc(memoryCollection, string(id), &memory)
+	if err != nil {
+		return goerr.Wrap(err, "failed to get memory", goerr.V("id", id))
+	}
+	if !found {
+		return goerr.Wrap(ErrNotFound, "memory not found", goerr.V("id", id))
+	}
+
+	memory.Score += delta
+	memory.UpdatedAt = time.Now()
+
+	if err := r.putDoc(memoryCollection, string(id), &memory); err != nil {
+		return goerr.Wrap(err, "failed to update memory score", goerr.V("id", id), goerr.V("delta", delta))
+	}
+
+	return nil
+}
+
+func (r *Local) DeleteMemoriesBelowScore(ctx context.Context, threshold float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	memories, err := r.allMemories()
+	if err != nil {
+		return goerr.Wrap(err, "failed to list memories to delete")
+	}
+
+	for _, memory := range memories {
+		if memory.Score >= threshold {
+			continue
+		}
+		path := r.docPath(memoryCollection, string(memory.ID))
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return goerr.Wrap(err, "failed to delete memory", goerr.V("id", memory.ID), goerr.V("threshold", threshold))
+		}
+	}
+
+	return nil
+}
+
+// getCursor reads the document pointed by a page token into v
+func (r *Local) getCursor(collection, token string, v any) error {
+	id, err := decodePageToken(token)
+	if err != nil {
+		return err
+	}
+
+	found, err := r.getDoc(collection, id, v)
+	if err != nil {
+		return goerr.Wrap(err, "failed to get document of page token", goerr.V("id", id))
+	}
+	if !found {
+		return goerr.Wrap(ErrInvalidPageToken, "document of page token not found", goerr.V("id", id))
+	}
+	return nil
+}
+
+// startAfter returns items ordered after cursor. items must be sorted by less.
+func startAfter[T any](items []T, cursor T, less func(a, b T) bool) []T {
+	i := sort.Search(len(items), func(i int) bool {
+		return less(cursor, items[i])
+	})
+	return items[i:]
+}
+
+// paginate applies offset and limit to a slice. limit <= 0 means no limit.
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// CosineDistance returns 1 - cosine similarity, the same measure as Firestore's
+// DistanceMeasureCosine. It returns false if the vectors can not be compared.
+func CosineDistance(a, b []float32) (float64, bool) {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0, false
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0, false
+	}
+
+	return 1 - dot/(math.Sqrt(normA)*math.Sqrt(normB)), true
+}
+
+// lookupField follows a dot separated field path in JSON decoded data
+func lookupField(data any, path []string) (any, bool) {
+	current := data
+	for _, key := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// evalOperator evaluates a Firestore style comparison between a stored field value and a query value
+func evalOperator(op string, fieldValue, queryValue any) (bool, error) {
+	switch op {
+	case "==":
+		return valuesEqual(fieldValue, queryValue), nil
+	case "!=":
+		return !valuesEqual(fieldValue, queryValue), nil
+	case "<", "<=", ">", ">=":
+		cmp, ok := compareValues(fieldValue, queryValue)
+		if !ok {
+			return false, nil
+		}
+		switch op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	case "array-contains":
+		arr, ok := fieldValue.([]any)
+		if !ok {
+			return false, nil
+		}
+		return containsValue(arr, queryValue), nil
+	case "array-contains-any":
+		arr, ok := fieldValue.([]any)
+		if !ok {
+			return false, nil
+		}
+		candidates, ok := queryValue.([]any)
+		if !ok {
+			return false, goerr.New("array-contains-any requires an array value")
+		}
+		for _, c := range candidates {
+			if containsValue(arr, c) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "in", "not-in":
+		candidates, ok := queryValue.([]any)
+		if !ok {
+			return false, goerr.New("operator requires an array value", goerr.V("operator", op))
+		}
+		found := containsValue(candidates, fieldValue)
+		if op == "in" {
+			return found, nil
+		}
+		return !found, nil
+	default:
+		return false, goerr.New("unsupported operator", goerr.V("operator", op))
+	}
+}
+
+func containsValue(arr []any, v any) bool {
+	for _, item := range arr {
+		if valuesEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func valuesEqual(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return fa == fb
+		}
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues compares two numbers or two strings. It returns false for other type combinations.
+func compareValues(a, b any) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	sa, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+	sb, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
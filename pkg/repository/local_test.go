@@ -0,0 +1,376 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"google.golang.org/genai"
+)
+
+func setupLocal(t *testing.T) *repository.Local {
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+	return repo
+}
+
+func TestLocalAlert(t *testing.T) {
+	repo := setupLocal(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	resolvedAt := now.Add(-90 * time.Minute)
+	alerts := []*model.Alert{
+		{
+			ID:         model.NewAlertID(),
+			Title:      "Old alert",
+			Data:       map[string]any{"severity": 3, "source": "guardduty"},
+			Attributes: []*model.Attribute{{Key: "src_ip", Value: "10.0.0.1", Type: model.AttributeTypeIPAddress}},
+			Status:     model.AlertStatusResolved,
+			Severity:   model.SeverityLow,
+			CreatedAt:  now.Add(-2 * time.Hour),
+			ResolvedAt: &resolvedAt,
+			Conclusion: model.ConclusionFalsePositive,
+		},
+		{
+			ID:        model.NewAlertID(),
+			Title:     "New alert",
+			Data:      map[string]any{"severity": 8, "source": "guardduty", "tags": []any{"ec2", "ssh"}},
+			Status:    model.AlertStatusNew,
+			Severity:  model.SeverityHigh,
+			CreatedAt: now,
+		},
+		{
+			ID:         model.NewAlertID(),
+			Title:      "Middle alert",
+			Data:       map[string]any{"severity": 5, "source": "scc", "resource": map[string]any{"type": "bucket"}},
+			Attributes: []*model.Attribute{{Key: "src_ip", Value: "10.0.0.1", Type: model.AttributeTypeIPAddress}},
+			Status:     model.AlertStatusInvestigating,
+			Severity:   model.SeverityHigh,
+			Assignee:   "alice",
+			CreatedAt:  now.Add(-1 * time.Hour),
+			MergedTo:   model.AlertID("merged-target"),
+		},
+	}
+	for _, a := range alerts {
+		gt.NoError(t, repo.PutAlert(ctx, a))
+	}
+
+	t.Run("get", func(t *testing.T) {
+		got, err := repo.GetAlert(ctx, alerts[0].ID)
+		gt.NoError(t, err)
+		gt.Equal(t, got.ID, alerts[0].ID)
+		gt.Equal(t, got.Title, alerts[0].Title)
+		gt.True(t, got.CreatedAt.Equal(alerts[0].CreatedAt))
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		_, err := repo.GetAlert(ctx, model.AlertID("non-existent-alert"))
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		// Separate repository not to change alerts listed below
+		repo := setupLocal(t)
+		a := &model.Alert{ID: model.NewAlertID(), Title: "Created alert", CreatedAt: now}
+		gt.NoError(t, repo.CreateAlert(ctx, a))
+
+		got, err := repo.GetAlert(ctx, a.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, got.Title, "Created alert")
+
+		dup := &model.Alert{ID: a.ID, Title: "Duplicated alert", CreatedAt: now}
+		err = repo.CreateAlert(ctx, dup)
+		gt.True(t, errors.Is(err, repository.ErrAlreadyExists))
+
+		got, err = repo.GetAlert(ctx, a.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, got.Title, "Created alert")
+	})
+
+	t.Run("list ordered by CreatedAt desc", func(t *testing.T) {
+		got, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 10})
+		gt.NoError(t, err)
+		gt.A(t, got).Length(3)
+		gt.Equal(t, got[0].Title, "New alert")
+		gt.Equal(t, got[1].Title, "Middle alert")
+		gt.Equal(t, got[2].Title, "Old alert")
+
+		paged, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Offset: 1, Limit: 1})
+		gt.NoError(t, err)
+		gt.A(t, paged).Length(1)
+		gt.Equal(t, paged[0].Title, "Middle alert")
+
+		empty, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Offset: 100, Limit: 10})
+		gt.NoError(t, err)
+		gt.A(t, empty).Length(0)
+	})
+
+	t.Run("list with page token", func(t *testing.T) {
+		first, token, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 2})
+		gt.NoError(t, err)
+		gt.A(t, first).Length(2)
+		gt.Equal(t, first[1].Title, "Middle alert")
+		gt.NotEqual(t, token, "")
+
+		second, token, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 2, PageToken: token})
+		gt.NoError(t, err)
+		gt.A(t, second).Length(1)
+		gt.Equal(t, second[0].Title, "Old alert")
+		gt.Equal(t, token, "")
+
+		_, _, err = repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 2, PageToken: "invalid/token"})
+		gt.True(t, errors.Is(err, repository.ErrInvalidPageToken))
+	})
+
+	t.Run("search with page token", func(t *testing.T) {
+		input := repository.SearchAlertsInput{Field: "source", Operator: "==", Value: "guardduty", Limit: 1}
+		first, token, err := repo.SearchAlerts(ctx, &input)
+		gt.NoError(t, err)
+		gt.A(t, first).Length(1)
+		gt.NotEqual(t, token, "")
+
+		input.PageToken = token
+		second, _, err := repo.SearchAlerts(ctx, &input)
+		gt.NoError(t, err)
+		gt.A(t, second).Length(1)
+		gt.NotEqual(t, second[0].ID, first[0].ID)
+	})
+
+	t.Run("list with filter", func(t *testing.T) {
+		yes, no := true, false
+		testCases := []struct {
+			name   string
+			input  repository.ListAlertsInput
+			titles []string
+		}{
+			{"status", repository.ListAlertsInput{Statuses: []model.AlertStatus{model.AlertStatusNew}}, []string{"New alert"}},
+			{"statuses", repository.ListAlertsInput{Statuses: []model.AlertStatus{model.AlertStatusNew, model.AlertStatusInvestigating}}, []string{"New alert", "Middle alert"}},
+			{"assignee", repository.ListAlertsInput{Assignee: "alice"}, []string{"Middle alert"}},
+			{"severity", repository.ListAlertsInput{Severity: model.SeverityHigh}, []string{"New alert", "Middle alert"}},
+			{"combined", repository.ListAlertsInput{Severity: model.SeverityHigh, Assignee: "bob"}, nil},
+			{"conclusion", repository.ListAlertsInput{Conclusion: model.ConclusionFalsePositive}, []string{"Old alert"}},
+			{"resolved", repository.ListAlertsInput{Resolved: &yes}, []string{"Old alert"}},
+			{"unresolved", repository.ListAlertsInput{Resolved: &no}, []string{"New alert", "Middle alert"}},
+			{"merged", repository.ListAlertsInput{Merged: &yes}, []string{"Middle alert"}},
+			{"not merged", repository.ListAlertsInput{Merged: &no}, []string{"New alert", "Old alert"}},
+			{"merged to", repository.ListAlertsInput{MergedTo: "merged-target"}, []string{"Middle alert"}},
+			{"created after", repository.ListAlertsInput{CreatedAfter: now.Add(-time.Hour)}, []string{"New alert", "Middle alert"}},
+			{"created range", repository.ListAlertsInput{CreatedAfter: now.Add(-3 * time.Hour), CreatedBefore: now.Add(-time.Hour)}, []string{"Old alert"}},
+			{"title keyword", repository.ListAlertsInput{TitleKeyword: "Middle"}, []string{"Middle alert"}},
+			{"attribute", repository.ListAlertsInput{AttributeKey: "src_ip", AttributeValue: "10.0.0.1"}, []string{"Middle alert", "Old alert"}},
+			{"ascending", repository.ListAlertsInput{Order: repository.SortAsc, Limit: 2}, []string{"Old alert", "Middle alert"}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, _, err := repo.ListAlerts(ctx, &tc.input)
+				gt.NoError(t, err)
+				gt.A(t, got).Length(len(tc.titles))
+				for i, title := range tc.titles {
+					gt.Equal(t, got[i].Title, title)
+				}
+			})
+		}
+	})
+
+	t.Run("list with invalid filter", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			input repository.ListAlertsInput
+		}{
+			{"multiple words", repository.ListAlertsInput{TitleKeyword: "new alert"}},
+			{"attribute without value", repository.ListAlertsInput{AttributeKey: "src_ip"}},
+			{"keyword and attribute", repository.ListAlertsInput{TitleKeyword: "new", AttributeKey: "src_ip", AttributeValue: "10.0.0.1"}},
+			{"reversed range", repository.ListAlertsInput{CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)}},
+			{"unknown order", repository.ListAlertsInput{Order: "random"}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, _, err := repo.ListAlerts(ctx, &tc.input)
+				gt.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			input repository.SearchAlertsInput
+			count int
+		}{
+			{"equal string", repository.SearchAlertsInput{Field: "source", Operator: "==", Value: "guardduty"}, 2},
+			{"not equal", repository.SearchAlertsInput{Field: "source", Operator: "!=", Value: "guardduty"}, 1},
+			{"greater than number", repository.SearchAlertsInput{Field: "severity", Operator: ">", Value: 4.0}, 2},
+			{"less or equal number", repository.SearchAlertsInput{Field: "severity", Operator: "<=", Value: 5.0}, 2},
+			{"nested field", repository.SearchAlertsInput{Field: "resource.type", Operator: "==", Value: "bucket"}, 1},
+			{"array contains", repository.SearchAlertsInput{Field: "tags", Operator: "array-contains", Value: "ssh"}, 1},
+			{"in", repository.SearchAlertsInput{Field: "source", Operator: "in", Value: []any{"scc", "other"}}, 1},
+			{"limit", repository.SearchAlertsInput{Field: "source", Operator: "==", Value: "guardduty", Limit: 1}, 1},
+			{"no results", repository.SearchAlertsInput{Field: "source", Operator: "==", Value: "nonexistent"}, 0},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				input := tc.input
+				got, _, err := repo.SearchAlerts(ctx, &input)
+				gt.NoError(t, err)
+				gt.A(t, got).Length(tc.count)
+			})
+		}
+	})
+
+	t.Run("search validation", func(t *testing.T) {
+		_, _, err := repo.SearchAlerts(ctx, &repository.SearchAlertsInput{Operator: "==", Value: "x"})
+		gt.Error(t, err)
+		_, _, err = repo.SearchAlerts(ctx, &repository.SearchAlertsInput{Field: "source", Value: "x"})
+		gt.Error(t, err)
+	})
+}
+
+func TestLocalSearchSimilarAlerts(t *testing.T) {
+	repo := setupLocal(t)
+	ctx := context.Background()
+
+	near := &model.Alert{ID: model.NewAlertID(), Title: "near", Embedding: firestore.Vector32{1, 0.1, 0}, CreatedAt: time.Now()}
+	nearest := &model.Alert{ID: model.NewAlertID(), Title: "nearest", Embedding: firestore.Vector32{1, 0, 0}, CreatedAt: time.Now()}
+	far := &model.Alert{ID: model.NewAlertID(), Title: "far", Embedding: firestore.Vector32{0, 0, 1}, CreatedAt: time.Now()}
+	noEmbedding := &model.Alert{ID: model.NewAlertID(), Title: "none", CreatedAt: time.Now()}
+	for _, a := range []*model.Alert{near, nearest, far, noEmbedding} {
+		gt.NoError(t, repo.PutAlert(ctx, a))
+	}
+
+	got, err := repo.SearchSimilarAlerts(ctx, []float32{1, 0, 0}, 0.5)
+	gt.NoError(t, err)
+	gt.A(t, got).Length(2)
+	gt.Equal(t, got[0].ID, nearest.ID)
+	gt.Equal(t, got[1].ID, near.ID)
+}
+
+func TestLocalHistory(t *testing.T) {
+	repo := setupLocal(t)
+	ctx := context.Background()
+
+	alertID := model.NewAlertID()
+	now := time.Now()
+	h1 := &model.History{
+		ID:        model.NewHistoryID(),
+		Title:     "first",
+		AlertID:   alertID,
+		CreatedAt: now.Add(-time.Hour),
+		UpdatedAt: now,
+		Contents:  []*genai.Content{genai.NewContentFromText("hello", genai.RoleUser)},
+	}
+	h2 := &model.History{ID: model.NewHistoryID(), Title: "second", AlertID: alertID, CreatedAt: now, UpdatedAt: now}
+	other := &model.History{ID: model.NewHistoryID(), Title: "other", AlertID: model.NewAlertID(), CreatedAt: now, UpdatedAt: now}
+	for _, h := range []*model.History{h1, h2, other} {
+		gt.NoError(t, repo.PutHistory(ctx, h))
+	}
+
+	got, err := repo.GetHistory(ctx, h1.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, got.Title, "first")
+	// Contents are not persisted in the repository
+	gt.A(t, got.Contents).Length(0)
+	gt.A(t, h1.Contents).Length(1)
+
+	_, err = repo.GetHistory(ctx, model.HistoryID("non-existent"))
+	gt.Error(t, err)
+
+	byAlert, err := repo.ListHistoryByAlert(ctx, alertID)
+	gt.NoError(t, err)
+	gt.A(t, byAlert).Length(2)
+	gt.Equal(t, byAlert[0].ID, h2.ID)
+	gt.Equal(t, byAlert[1].ID, h1.ID)
+
+	all, token, err := repo.ListHistory(ctx, &repository.ListHistoryInput{Limit: 10})
+	gt.NoError(t, err)
+	gt.A(t, all).Length(3)
+	gt.Equal(t, token, "")
+
+	page, token, err := repo.ListHistory(ctx, &repository.ListHistoryInput{Limit: 2})
+	gt.NoError(t, err)
+	gt.A(t, page).Length(2)
+	next, _, err := repo.ListHistory(ctx, &repository.ListHistoryInput{Limit: 2, PageToken: token})
+	gt.NoError(t, err)
+	gt.A(t, next).Length(1)
+	gt.Equal(t, next[0].ID, all[2].ID)
+}
+
+func TestLocalMemory(t *testing.T) {
+	repo := setupLocal(t)
+	ctx := context.Background()
+
+	m1 := &model.Memory{ID: model.NewMemoryID(), Claim: "close", Embedding: firestore.Vector32{1, 0}, Score: 0}
+	m2 := &model.Memory{ID: model.NewMemoryID(), Claim: "closer", Embedding: firestore.Vector32{1, 0.01}, Score: 0}
+	m3 := &model.Memory{ID: model.NewMemoryID(), Claim: "orthogonal", Embedding: firestore.Vector32{0, 1}, Score: 0}
+	for _, m := range []*model.Memory{m1, m2, m3} {
+		gt.NoError(t, repo.PutMemory(ctx, m))
+	}
+
+	got, err := repo.GetMemory(ctx, m1.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, got.Claim, "close")
+
+	similar, err := repo.SearchMemories(ctx, firestore.Vector32{1, 0}, 0.5, 1)
+	gt.NoError(t, err)
+	gt.A(t, similar).Length(1)
+	gt.Equal(t, similar[0].ID, m1.ID)
+
+	gt.NoError(t, repo.UpdateMemoryScore(ctx, m1.ID, -5))
+	gt.NoError(t, repo.UpdateMemoryScore(ctx, m2.ID, 2))
+	gt.Error(t, repo.UpdateMemoryScore(ctx, model.MemoryID("non-existent"), 1))
+
+	updated, err := repo.GetMemory(ctx, m2.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, updated.Score, 2.0)
+
+	gt.NoError(t, repo.DeleteMemoriesBelowScore(ctx, -3.0))
+
+	_, err = repo.GetMemory(ctx, m1.ID)
+	gt.Error(t, err)
+	_, err = repo.GetMemory(ctx, m2.ID)
+	gt.NoError(t, err)
+	_, err = repo.GetMemory(ctx, m3.ID)
+	gt.NoError(t, err)
+}
+
+func TestLocalActivity(t *testing.T) {
+	repo := setupLocal(t)
+	ctx := context.Background()
+
+	alertID := model.NewAlertID()
+	now := time.Now()
+
+	second := model.NewActivity(alertID, model.ActivityStatusChanged, "analyst")
+	second.From = string(model.AlertStatusNew)
+	second.To = string(model.AlertStatusAcknowledged)
+	second.CreatedAt = now
+	first := model.NewActivity(alertID, model.ActivityCreated, "")
+	first.CreatedAt = now.Add(-time.Minute)
+	other := model.NewActivity(model.NewAlertID(), model.ActivityCreated, "")
+
+	for _, a := range []*model.Activity{second, first, other} {
+		gt.NoError(t, repo.AddActivity(ctx, a))
+	}
+
+	t.Run("list in chronological order", func(t *testing.T) {
+		got, err := repo.ListActivitiesByAlert(ctx, alertID)
+		gt.NoError(t, err)
+		gt.A(t, got).Length(2)
+		gt.Equal(t, got[0].ID, first.ID)
+		gt.Equal(t, got[1].ID, second.ID)
+		gt.Equal(t, got[1].To, string(model.AlertStatusAcknowledged))
+	})
+
+	t.Run("activity can not be overwritten", func(t *testing.T) {
+		gt.Error(t, repo.AddActivity(ctx, second))
+	})
+}
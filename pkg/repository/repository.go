@@ -2,11 +2,61 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/leveret/pkg/model"
 )
 
+var (
+	// ErrNotFound is returned when a requested document does not exist
+	ErrNotFound = goerr.New("not found")
+
+	// ErrInvalidPageToken is returned when a page token is malformed or
+	// points to a document that no longer exists
+	ErrInvalidPageToken = goerr.New("invalid page token")
+
+	// ErrAlreadyExists is returned when a document to be created exists
+	ErrAlreadyExists = goerr.New("already exists")
+)
+
+// encodePageToken returns an opaque page token pointing to the last document
+// of a page
+func encodePageToken(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// decodePageToken returns the document ID in a page token
+func decodePageToken(token string) (string, error) {
+	id, err := base64.RawURLEncoding.DecodeString(token)
+	// Document IDs never contain path separator
+	if err != nil || len(id) == 0 || strings.ContainsAny(string(id), `/\`) {
+		return "", goerr.Wrap(ErrInvalidPageToken, "failed to decode page token", goerr.V("token", token))
+	}
+	return string(id), nil
+}
+
+// nextPageToken returns a token for the page after the last document. It
+// returns empty if the page is not full, i.e. there are no more documents.
+func nextPageToken(count, limit int, lastID string) string {
+	if limit <= 0 || count < limit || lastID == "" {
+		return ""
+	}
+	return encodePageToken(lastID)
+}
+
+// lastAlertID returns ID of the last alert in the page, or empty
+func lastAlertID(alerts []*model.Alert) string {
+	if len(alerts) == 0 {
+		return ""
+	}
+	return string(alerts[len(alerts)-1].ID)
+}
+
 // SearchAlertsInput contains parameters for searching alerts
 type SearchAlertsInput struct {
 	Field    string // Field path within Data (auto-prefixed with "Data.")
@@ -14,6 +64,182 @@ type SearchAlertsInput struct {
 	Value    any    // Value to compare
 	Limit    int    // Max results (default: 10, max: 100)
 	Offset   int    // Skip count for pagination
+
+	// PageToken is a token returned by the previous call to continue from.
+	// Prefer it to Offset because skipped documents are also read.
+	PageToken string
+}
+
+// SearchAttributesInput contains parameters for searching alerts by
+// indicator in attributes, e.g. IP address, regardless of the alert source
+type SearchAttributesInput struct {
+	Type      model.AttributeType // Empty matches all types
+	Value     string
+	Limit     int    // Max results (default: 10, max: 100)
+	PageToken string // Token returned by the previous call to continue from
+}
+
+// keywords returns keywords to look up in Alert.Keywords. Any of them matches.
+func (x *SearchAttributesInput) keywords() []string {
+	if x.Type != "" {
+		return []string{model.IOCKeyword(x.Type, x.Value)}
+	}
+
+	keywords := make([]string, len(model.AttributeTypes))
+	for i, t := range model.AttributeTypes {
+		keywords[i] = model.IOCKeyword(t, x.Value)
+	}
+	return keywords
+}
+
+// validate checks input and sets defaults
+func (x *SearchAttributesInput) validate() error {
+	if x.Value == "" {
+		return goerr.New("attribute value is required")
+	}
+	if x.Type != "" {
+		if err := x.Type.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if x.Limit <= 0 {
+		x.Limit = 10
+	}
+	if x.Limit > 100 {
+		x.Limit = 100
+	}
+	return nil
+}
+
+// SortOrder is an order of alerts by creation time
+type SortOrder string
+
+const (
+	SortDesc SortOrder = "desc" // Newest first (default)
+	SortAsc  SortOrder = "asc"  // Oldest first
+)
+
+// ListAlertsInput contains filters and pagination for ListAlerts. Zero value
+// fields match all alerts. Alerts saved before status and keywords were
+// introduced have no stored value and do not match Statuses, TitleKeyword
+// and Attribute filters until they are backfilled by the migrate command.
+type ListAlertsInput struct {
+	Statuses   []model.AlertStatus // Match any of them
+	Assignee   string
+	Severity   model.Severity
+	Conclusion model.Conclusion
+	Resolved   *bool         // true: ResolvedAt is set, false: not set
+	Merged     *bool         // true: merged into another alert, false: not merged
+	MergedTo   model.AlertID // Alerts merged directly into the alert
+
+	CreatedAfter  time.Time // Inclusive
+	CreatedBefore time.Time // Exclusive
+
+	// TitleKeyword matches alerts whose title has the word. Only one of
+	// TitleKeyword and AttributeKey/AttributeValue can be set because
+	// both are evaluated on Keywords field.
+	TitleKeyword   string
+	AttributeKey   string
+	AttributeValue string
+
+	Order     SortOrder
+	Offset    int
+	Limit     int
+	PageToken string // Token returned by the previous call to continue from
+}
+
+// ListHistoryInput contains pagination for ListHistory
+type ListHistoryInput struct {
+	Offset    int
+	Limit     int
+	PageToken string // Token returned by the previous call to continue from
+}
+
+// ListIncidentsInput contains filters and pagination for ListIncidents
+type ListIncidentsInput struct {
+	Status    *model.AlertStatus // Filter by status if not nil
+	Limit     int
+	PageToken string // Token returned by the previous call to continue from
+}
+
+// keyword returns the keyword to look up in Alert.Keywords, or empty if no
+// keyword filter is given
+func (x *ListAlertsInput) keyword() string {
+	if x.TitleKeyword != "" {
+		return model.TitleKeyword(x.TitleKeyword)
+	}
+	if x.AttributeKey != "" {
+		return model.AttributeKeyword(x.AttributeKey, x.AttributeValue)
+	}
+	return ""
+}
+
+// validate checks if the input can be evaluated as a single query
+func (x *ListAlertsInput) validate() error {
+	switch x.Order {
+	case "", SortDesc, SortAsc:
+	default:
+		return goerr.New("invalid sort order", goerr.V("order", x.Order))
+	}
+
+	if x.TitleKeyword != "" {
+		if words := model.SplitWords(x.TitleKeyword); len(words) != 1 || words[0] != strings.ToLower(x.TitleKeyword) {
+			return goerr.New("title keyword must be a single word", goerr.V("keyword", x.TitleKeyword))
+		}
+	}
+	if (x.AttributeKey == "") != (x.AttributeValue == "") {
+		return goerr.New("both attribute key and value are required",
+			goerr.V("key", x.AttributeKey), goerr.V("value", x.AttributeValue))
+	}
+	if x.TitleKeyword != "" && x.AttributeKey != "" {
+		return goerr.New("title keyword and attribute filters can not be combined")
+	}
+	if x.MergedTo != "" && x.Merged != nil && !*x.Merged {
+		return goerr.New("merged-to filter can not be combined with unmerged filter", goerr.V("mergedTo", x.MergedTo))
+	}
+	if !x.CreatedAfter.IsZero() && !x.CreatedBefore.IsZero() && !x.CreatedAfter.Before(x.CreatedBefore) {
+		return goerr.New("invalid created time range",
+			goerr.V("after", x.CreatedAfter), goerr.V("before", x.CreatedBefore))
+	}
+
+	return nil
+}
+
+// match evaluates the input in memory. It must be consistent with the
+// Firestore query built by Firestore.ListAlerts.
+func (x *ListAlertsInput) match(alert *model.Alert) bool {
+	if len(x.Statuses) > 0 && !slices.Contains(x.Statuses, alert.Status) {
+		return false
+	}
+	if x.Assignee != "" && alert.Assignee != x.Assignee {
+		return false
+	}
+	if x.Severity != "" && alert.Severity != x.Severity {
+		return false
+	}
+	if x.Conclusion != "" && alert.Conclusion != x.Conclusion {
+		return false
+	}
+	if x.Resolved != nil && *x.Resolved != (alert.ResolvedAt != nil) {
+		return false
+	}
+	if x.Merged != nil && *x.Merged != (alert.MergedTo != "") {
+		return false
+	}
+	if x.MergedTo != "" && alert.MergedTo != x.MergedTo {
+		return false
+	}
+	if !x.CreatedAfter.IsZero() && alert.CreatedAt.Before(x.CreatedAfter) {
+		return false
+	}
+	if !x.CreatedBefore.IsZero() && !alert.CreatedAt.Before(x.CreatedBefore) {
+		return false
+	}
+	if keyword := x.keyword(); keyword != "" && !slices.Contains(alert.Keywords, keyword) {
+		return false
+	}
+	return true
 }
 
 // Repository defines the interface for alert data persistence
@@ -21,14 +247,27 @@ type Repository interface {
 	// PutAlert saves an alert to the repository
 	PutAlert(ctx context.Context, alert *model.Alert) error
 
+	// CreateAlert saves a new alert atomically. It fails with
+	// ErrAlreadyExists if an alert with the same ID exists.
+	CreateAlert(ctx context.Context, alert *model.Alert) error
+
 	// GetAlert retrieves an alert by ID
 	GetAlert(ctx context.Context, id model.AlertID) (*model.Alert, error)
 
-	// ListAlerts retrieves alerts with optional filters
-	ListAlerts(ctx context.Context, offset, limit int) ([]*model.Alert, error)
+	// ListAlerts retrieves alerts matching the input in order of creation
+	// time, newest first by default. It also returns a token of the next
+	// page, which is empty if there are no more alerts.
+	ListAlerts(ctx context.Context, input *ListAlertsInput) ([]*model.Alert, string, error)
 
-	// SearchAlerts searches alerts by field conditions in Data
-	SearchAlerts(ctx context.Context, input *SearchAlertsInput) ([]*model.Alert, error)
+	// SearchAlerts searches alerts by field conditions in Data. It also
+	// returns a token of the next page, which is empty if there are no more
+	// alerts.
+	SearchAlerts(ctx context.Context, input *SearchAlertsInput) ([]*model.Alert, string, error)
+
+	// SearchAlertsByAttribute searches alerts having the indicator in their
+	// attributes in descending order of creation time. It also returns a
+	// token of the next page, which is empty if there are no more alerts.
+	SearchAlertsByAttribute(ctx context.Context, input *SearchAttributesInput) ([]*model.Alert, string, error)
 
 	// SearchSimilarAlerts performs vector search to find similar alerts
 	SearchSimilarAlerts(ctx context.Context, embedding []float32, threshold float64) ([]*model.Alert, error)
@@ -39,12 +278,41 @@ type Repository interface {
 	// GetHistory retrieves a conversation history by ID
 	GetHistory(ctx context.Context, id model.HistoryID) (*model.History, error)
 
-	// ListHistory retrieves conversation histories
-	ListHistory(ctx context.Context, offset, limit int) ([]*model.History, error)
+	// ListHistory retrieves conversation histories in descending order of
+	// creation time. It also returns a token of the next page, which is empty
+	// if there are no more histories.
+	ListHistory(ctx context.Context, input *ListHistoryInput) ([]*model.History, string, error)
 
 	// ListHistoryByAlert retrieves conversation histories for a specific alert
 	ListHistoryByAlert(ctx context.Context, alertID model.AlertID) ([]*model.History, error)
 
+	// ListHistoryByIncident retrieves conversation histories for a specific incident
+	ListHistoryByIncident(ctx context.Context, incidentID model.IncidentID) ([]*model.History, error)
+
+	// AddActivity appends an activity to the timeline of an alert. Existing
+	// activities can not be overwritten.
+	AddActivity(ctx context.Context, activity *model.Activity) error
+
+	// ListActivitiesByAlert retrieves the timeline of an alert in ascending order of time
+	ListActivitiesByAlert(ctx context.Context, alertID model.AlertID) ([]*model.Activity, error)
+
+	// ListActivitiesByIncident retrieves the timeline of an incident in ascending order of time
+	ListActivitiesByIncident(ctx context.Context, incidentID model.IncidentID) ([]*model.Activity, error)
+
+	// PutIncident saves an incident to the repository
+	PutIncident(ctx context.Context, incident *model.Incident) error
+
+	// GetIncident retrieves an incident by ID
+	GetIncident(ctx context.Context, id model.IncidentID) (*model.Incident, error)
+
+	// ListIncidents retrieves incidents in descending order of creation time.
+	// It also returns a token of the next page, which is empty if there are
+	// no more incidents.
+	ListIncidents(ctx context.Context, input *ListIncidentsInput) ([]*model.Incident, string, error)
+
+	// ListIncidentsByAlert retrieves incidents having the alert as a member
+	ListIncidentsByAlert(ctx context.Context, alertID model.AlertID) ([]*model.Incident, error)
+
 	// PutMemory saves a memory to the repository
 	PutMemory(ctx context.Context, memory *model.Memory) error
 
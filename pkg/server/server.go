@@ -0,0 +1,246 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/usecase/ingest"
+	"github.com/m-mizutani/leveret/pkg/utils/logging"
+)
+
+// defaultMaxBodySize limits size of a request body of alert endpoints
+const defaultMaxBodySize = 10 * 1024 * 1024
+
+// Server is an HTTP handler that receives alerts from detection pipelines.
+// Each source (e.g. "guardduty", "scc") has its own ingest UseCase with a
+// workflow engine built from a dedicated policy directory.
+type Server struct {
+	mux         *http.ServeMux
+	sources     map[string]*ingest.UseCase
+	readiness   func(ctx context.Context) error
+	maxBodySize int64
+}
+
+var _ http.Handler = &Server{}
+
+// Option is a functional option for Server
+type Option func(*Server)
+
+// WithReadiness sets a check function used by the readiness endpoint
+func WithReadiness(check func(ctx context.Context) error) Option {
+	return func(s *Server) {
+		s.readiness = check
+	}
+}
+
+// WithMaxBodySize sets the maximum size of a request body in bytes
+func WithMaxBodySize(size int64) Option {
+	return func(s *Server) {
+		if size > 0 {
+			s.maxBodySize = size
+		}
+	}
+}
+
+// New creates a new Server. sources maps a path segment of
+// /alert/{source} to an ingest UseCase.
+func New(sources map[string]*ingest.UseCase, opts ...Option) *Server {
+	s := &Server{
+		mux:         http.NewServeMux(),
+		sources:     sources,
+		maxBodySize: defaultMaxBodySize,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("GET /ready", s.handleReady)
+	s.mux.HandleFunc("POST /alert/{source}", s.handleAlert)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(r.Context(), w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if s.readiness != nil {
+		if err := s.readiness(r.Context()); err != nil {
+			logging.From(r.Context()).Warn("readiness check failed", "error", err)
+			writeJSON(r.Context(), w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "error": err.Error()})
+			return
+		}
+	}
+
+	writeJSON(r.Context(), w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// alertResponse is the response body of alert endpoints
+type alertResponse struct {
+	Results []*resultView `json:"results"`
+	Summary *summaryView  `json:"summary"`
+}
+
+type resultView struct {
+	Source   string             `json:"source"`
+	Rejected bool               `json:"rejected"`
+	Error    string             `json:"error,omitempty"`
+	Alerts   []*alertResultView `json:"alerts"`
+}
+
+type alertResultView struct {
+	Title    string        `json:"title"`
+	Action   ingest.Action `json:"action"`
+	Severity string        `json:"severity,omitempty"`
+	Note     string        `json:"note,omitempty"`
+	AlertID  model.AlertID `json:"alert_id,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type summaryView struct {
+	Inputs    int `json:"inputs"`
+	Accepted  int `json:"accepted"`
+	Notified  int `json:"notified"`
+	Discarded int `json:"discarded"`
+	Errored   int `json:"errored"`
+}
+
+// idempotencyKeyHeader is a request header identifying a request among its
+// retries
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// handleAlert ingests alert JSON of a source. The body can be a single JSON
+// document, a JSON array or NDJSON. It responds 500 if any input failed so
+// that senders can retry. Alert IDs are derived from the Idempotency-Key
+// header, or the hash of the body if not given, so that a retry does not
+// duplicate alerts already saved.
+func (s *Server) handleAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.From(ctx)
+
+	source := r.PathValue("source")
+	uc, ok := s.sources[source]
+	if !ok {
+		writeJSON(ctx, w, http.StatusNotFound, map[string]string{"error": "unknown alert source: " + source})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	if err != nil {
+		status := http.StatusBadRequest
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(ctx, w, status, map[string]string{"error": err.Error()})
+		return
+	}
+
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		hash := sha256.Sum256(body)
+		key = hex.EncodeToString(hash[:])
+	}
+
+	// Decode all inputs first to reject malformed requests before processing any of them
+	var inputs []*ingest.Input
+	for input, err := range ingest.Decode(bytes.NewReader(body), source) {
+		if err != nil {
+			writeJSON(ctx, w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		// Source has the index of the input in the body
+		input.Key = key + "/" + input.Source
+		inputs = append(inputs, input)
+	}
+	if len(inputs) == 0 {
+		writeJSON(ctx, w, http.StatusBadRequest, map[string]string{"error": "no alert data in request body"})
+		return
+	}
+
+	resp := &alertResponse{Results: []*resultView{}}
+	summary := uc.Run(ctx, sliceInputs(inputs), func(result *ingest.Result) {
+		resp.Results = append(resp.Results, newResultView(result))
+		if result.Err != nil {
+			logger.Error("failed to ingest alert", "source", result.Source, "error", result.Err)
+		}
+		for _, a := range result.Alerts {
+			if a.Err != nil {
+				logger.Error("failed to save alert", "source", result.Source, "title", a.Title, "error", a.Err)
+			}
+		}
+	})
+	resp.Summary = &summaryView{
+		Inputs:    summary.Inputs,
+		Accepted:  summary.Accepted,
+		Notified:  summary.Notified,
+		Discarded: summary.Discarded,
+		Errored:   summary.Errored,
+	}
+
+	status := http.StatusOK
+	if summary.Errored > 0 {
+		status = http.StatusInternalServerError
+	}
+	writeJSON(ctx, w, status, resp)
+}
+
+func sliceInputs(inputs []*ingest.Input) iter.Seq2[*ingest.Input, error] {
+	return func(yield func(*ingest.Input, error) bool) {
+		for _, input := range inputs {
+			if !yield(input, nil) {
+				return
+			}
+		}
+	}
+}
+
+func newResultView(result *ingest.Result) *resultView {
+	v := &resultView{
+		Source:   result.Source,
+		Rejected: result.Rejected,
+		Alerts:   []*alertResultView{},
+	}
+	if result.Err != nil {
+		v.Error = result.Err.Error()
+	}
+
+	for _, a := range result.Alerts {
+		av := &alertResultView{
+			Title:    a.Title,
+			Action:   a.Action,
+			Severity: a.Severity,
+			Note:     a.Note,
+			AlertID:  a.AlertID,
+		}
+		if a.Err != nil {
+			av.Error = a.Err.Error()
+		}
+		v.Alerts = append(v.Alerts, av)
+	}
+
+	return v
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logging.From(ctx).Error("failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,179 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/server"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/usecase/ingest"
+	"github.com/m-mizutani/leveret/pkg/workflow"
+	"google.golang.org/genai"
+)
+
+// mockGemini returns a fixed alert summary
+type mockGemini struct{}
+
+func (m *mockGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	text := `{"title": "summarized", "description": "summarized alert", "attributes": []}`
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(text, genai.RoleModel)}},
+	}, nil
+}
+
+func (m *mockGemini) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		yield(m.GenerateContent(ctx, contents, config))
+	}
+}
+
+func (m *mockGemini) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockGemini) Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error) {
+	return make(firestore.Vector32, dimensions), nil
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := t.TempDir()
+	gt.NoError(t, os.WriteFile(filepath.Join(policyDir, "ingest.rego"), []byte(`package ingest
+
+alert contains {
+	"title": input.title,
+	"description": "",
+	"attributes": [],
+} if {
+	input.title != ""
+}
+`), 0644))
+
+	engine, err := workflow.New(ctx, policyDir, nil, nil)
+	gt.NoError(t, err)
+
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	ready := true
+	srv := httptest.NewServer(server.New(
+		map[string]*ingest.UseCase{
+			"guardduty": ingest.New(alert.New(repo, &mockGemini{}), ingest.WithEngine(engine)),
+		},
+		server.WithReadiness(func(ctx context.Context) error {
+			if !ready {
+				return errors.New("not ready")
+			}
+			return nil
+		}),
+	))
+	defer srv.Close()
+
+	t.Run("health", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/health")
+		gt.NoError(t, err)
+		defer resp.Body.Close()
+		gt.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/ready")
+		gt.NoError(t, err)
+		resp.Body.Close()
+		gt.Equal(t, resp.StatusCode, http.StatusOK)
+
+		ready = false
+		defer func() { ready = true }()
+		resp, err = http.Get(srv.URL + "/ready")
+		gt.NoError(t, err)
+		resp.Body.Close()
+		gt.Equal(t, resp.StatusCode, http.StatusServiceUnavailable)
+	})
+
+	t.Run("ingest alerts", func(t *testing.T) {
+		body := `[{"title": "suspicious login"}, {"title": ""}]`
+		resp, err := http.Post(srv.URL+"/alert/guardduty", "application/json", strings.NewReader(body))
+		gt.NoError(t, err)
+		defer resp.Body.Close()
+		gt.Equal(t, resp.StatusCode, http.StatusOK)
+
+		var result struct {
+			Results []struct {
+				Rejected bool `json:"rejected"`
+				Alerts   []struct {
+					Action  string `json:"action"`
+					AlertID string `json:"alert_id"`
+				} `json:"alerts"`
+			} `json:"results"`
+			Summary struct {
+				Inputs    int `json:"inputs"`
+				Accepted  int `json:"accepted"`
+				Discarded int `json:"discarded"`
+			} `json:"summary"`
+		}
+		gt.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		gt.Equal(t, result.Summary.Inputs, 2)
+		gt.Equal(t, result.Summary.Accepted, 1)
+		gt.Equal(t, result.Summary.Discarded, 1)
+
+		alerts, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 10})
+		gt.NoError(t, err)
+		gt.A(t, alerts).Length(1)
+	})
+
+	t.Run("retry does not duplicate alerts", func(t *testing.T) {
+		post := func(body, key string) {
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/alert/guardduty", strings.NewReader(body))
+			gt.NoError(t, err)
+			if key != "" {
+				req.Header.Set("Idempotency-Key", key)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			gt.NoError(t, err)
+			resp.Body.Close()
+			gt.Equal(t, resp.StatusCode, http.StatusOK)
+		}
+		count := func() int {
+			alerts, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 100})
+			gt.NoError(t, err)
+			return len(alerts)
+		}
+
+		before := count()
+		post(`{"title": "retried alert"}`, "")
+		post(`{"title": "retried alert"}`, "")
+		gt.Equal(t, count(), before+1)
+
+		// Same body with different keys is ingested as different requests
+		post(`{"title": "keyed alert"}`, "req-1")
+		post(`{"title": "keyed alert"}`, "req-1")
+		post(`{"title": "keyed alert"}`, "req-2")
+		gt.Equal(t, count(), before+3)
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		resp, err := http.Post(srv.URL+"/alert/unknown", "application/json", strings.NewReader(`{}`))
+		gt.NoError(t, err)
+		resp.Body.Close()
+		gt.Equal(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		resp, err := http.Post(srv.URL+"/alert/guardduty", "application/json", strings.NewReader(`{invalid`))
+		gt.NoError(t, err)
+		resp.Body.Close()
+		gt.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+}
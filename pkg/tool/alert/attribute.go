@@ -0,0 +1,122 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/tool"
+	"github.com/urfave/cli/v3"
+	"google.golang.org/genai"
+)
+
+type searchByAttributeInput struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Limit     int    `json:"limit"`
+	PageToken string `json:"page_token"`
+}
+
+type searchByAttribute struct {
+	repo repository.Repository
+}
+
+// NewSearchByAttribute creates a new search_alerts_by_attribute tool
+func NewSearchByAttribute() *searchByAttribute {
+	return &searchByAttribute{}
+}
+
+// Flags returns CLI flags for this tool
+func (s *searchByAttribute) Flags() []cli.Flag {
+	return nil
+}
+
+// Init initializes the tool
+func (s *searchByAttribute) Init(ctx context.Context, client *tool.Client) (bool, error) {
+	s.repo = client.Repo
+	return s.repo != nil, nil
+}
+
+// Prompt returns additional information to be added to the system prompt
+func (s *searchByAttribute) Prompt(ctx context.Context) string {
+	return ""
+}
+
+// Spec returns the tool specification for Gemini function calling
+func (s *searchByAttribute) Spec() *genai.Tool {
+	types := make([]string, len(model.AttributeTypes))
+	for i, t := range model.AttributeTypes {
+		types[i] = string(t)
+	}
+
+	return &genai.Tool{
+		FunctionDeclarations: []*genai.FunctionDeclaration{
+			{
+				Name:        "search_alerts_by_attribute",
+				Description: `Search alerts having an indicator (IOC) such as IP address, domain or file hash in their attributes, regardless of alert source and field names in the original data. Use it to pivot from an indicator of the current alert to other alerts.`,
+				Parameters: &genai.Schema{
+					Type: genai.TypeObject,
+					Properties: map[string]*genai.Schema{
+						"value": {
+							Type:        genai.TypeString,
+							Description: "Indicator value, e.g. 192.0.2.1 or example.com",
+						},
+						"type": {
+							Type:        genai.TypeString,
+							Description: "Attribute type of the indicator. Omit it to match all types",
+							Enum:        types,
+						},
+						"limit": {
+							Type:        genai.TypeInteger,
+							Description: "Max results (default: 10, max: 100)",
+						},
+						"page_token": {
+							Type:        genai.TypeString,
+							Description: "Token to get the next page, returned as next_page_token by the previous search with the same indicator",
+						},
+					},
+					Required: []string{"value"},
+				},
+			},
+		},
+	}
+}
+
+// Execute runs the tool with the given function call
+func (s *searchByAttribute) Execute(ctx context.Context, fc genai.FunctionCall) (*genai.FunctionResponse, error) {
+	paramsJSON, err := json.Marshal(fc.Args)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal function arguments")
+	}
+
+	var input searchByAttributeInput
+	if err := json.Unmarshal(paramsJSON, &input); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse input parameters")
+	}
+
+	fmt.Printf("🔍 属性でアラート検索中: %s %s\n", input.Type, input.Value)
+
+	alerts, nextPageToken, err := s.repo.SearchAlertsByAttribute(ctx, &repository.SearchAttributesInput{
+		Type:      model.AttributeType(input.Type),
+		Value:     input.Value,
+		Limit:     input.Limit,
+		PageToken: input.PageToken,
+	})
+	if err != nil {
+		fmt.Printf("❌ アラート検索エラー: %v\n", err)
+		return nil, goerr.Wrap(err, "failed to search alerts by attribute")
+	}
+
+	response := map[string]any{"result": formatResult(alerts)}
+	if nextPageToken != "" {
+		response["next_page_token"] = nextPageToken
+	}
+
+	return &genai.FunctionResponse{
+		Name:     fc.Name,
+		Response: response,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package alert_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/tool"
+	"github.com/m-mizutani/leveret/pkg/tool/alert"
+	"google.golang.org/genai"
+)
+
+func TestSearchByAttribute(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	now := time.Now()
+	alerts := []*model.Alert{
+		{
+			ID:         model.NewAlertID(),
+			Title:      "GuardDuty finding",
+			Attributes: []*model.Attribute{{Key: "remote_ip", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress}},
+			CreatedAt:  now.Add(-time.Hour),
+		},
+		{
+			ID:         model.NewAlertID(),
+			Title:      "SCC finding",
+			Attributes: []*model.Attribute{{Key: "source_ip", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress}},
+			CreatedAt:  now,
+		},
+		{
+			ID:         model.NewAlertID(),
+			Title:      "Phishing mail",
+			Attributes: []*model.Attribute{{Key: "sender_domain", Value: "Example.COM", Type: model.AttributeTypeDomain}},
+			CreatedAt:  now,
+		},
+	}
+	for _, a := range alerts {
+		gt.NoError(t, repo.PutAlert(ctx, a))
+	}
+
+	s := alert.NewSearchByAttribute()
+	enabled, err := s.Init(ctx, &tool.Client{Repo: repo})
+	gt.NoError(t, err)
+	gt.True(t, enabled)
+
+	t.Run("ip address across sources", func(t *testing.T) {
+		resp, err := s.Execute(ctx, genai.FunctionCall{
+			Name: "search_alerts_by_attribute",
+			Args: map[string]any{"type": "ip_address", "value": "192.0.2.1"},
+		})
+		gt.NoError(t, err)
+		result := resp.Response["result"].(string)
+		gt.S(t, result).Contains("Found 2 alert(s)")
+		gt.S(t, result).Contains(string(alerts[0].ID))
+		gt.S(t, result).Contains(string(alerts[1].ID))
+	})
+
+	t.Run("domain is case insensitive without type", func(t *testing.T) {
+		resp, err := s.Execute(ctx, genai.FunctionCall{
+			Name: "search_alerts_by_attribute",
+			Args: map[string]any{"value": "example.com"},
+		})
+		gt.NoError(t, err)
+		gt.S(t, resp.Response["result"].(string)).Contains(string(alerts[2].ID))
+	})
+
+	t.Run("next page", func(t *testing.T) {
+		resp, err := s.Execute(ctx, genai.FunctionCall{
+			Name: "search_alerts_by_attribute",
+			Args: map[string]any{"value": "192.0.2.1", "limit": 1},
+		})
+		gt.NoError(t, err)
+		gt.S(t, resp.Response["result"].(string)).Contains(string(alerts[1].ID))
+
+		token, ok := resp.Response["next_page_token"].(string)
+		gt.True(t, ok)
+		resp, err = s.Execute(ctx, genai.FunctionCall{
+			Name: "search_alerts_by_attribute",
+			Args: map[string]any{"value": "192.0.2.1", "limit": 1, "page_token": token},
+		})
+		gt.NoError(t, err)
+		gt.S(t, resp.Response["result"].(string)).Contains(string(alerts[0].ID))
+	})
+}
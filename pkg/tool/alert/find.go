@@ -0,0 +1,130 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/tool"
+	usecase "github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/urfave/cli/v3"
+	"google.golang.org/genai"
+)
+
+type findAlertsInput struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+type findAlerts struct {
+	uc *usecase.UseCase
+}
+
+// NewFindAlerts creates a new find_alerts tool
+func NewFindAlerts() *findAlerts {
+	return &findAlerts{}
+}
+
+// Flags returns CLI flags for this tool
+func (f *findAlerts) Flags() []cli.Flag {
+	return nil
+}
+
+// Init initializes the tool. It requires both repository and Gemini to
+// embed the query.
+func (f *findAlerts) Init(ctx context.Context, client *tool.Client) (bool, error) {
+	if client.Repo == nil || client.Gemini == nil {
+		return false, nil
+	}
+	f.uc = usecase.New(client.Repo, client.Gemini, usecase.WithOutput(io.Discard))
+	return true, nil
+}
+
+// Prompt returns additional information to be added to the system prompt
+func (f *findAlerts) Prompt(ctx context.Context) string {
+	return ""
+}
+
+// Spec returns the tool specification for Gemini function calling
+func (f *findAlerts) Spec() *genai.Tool {
+	return &genai.Tool{
+		FunctionDeclarations: []*genai.FunctionDeclaration{
+			{
+				Name:        "find_alerts",
+				Description: `Find alerts by natural language query. It combines semantic similarity, words in alert title and indicators (IP address, domain, etc.) in attributes, and returns alerts ranked by relevance. Use it when exact field paths in alert data are unknown; use search_alerts for exact field conditions.`,
+				Parameters: &genai.Schema{
+					Type: genai.TypeObject,
+					Properties: map[string]*genai.Schema{
+						"query": {
+							Type:        genai.TypeString,
+							Description: `Natural language query, e.g. "SSH brute force from 192.0.2.1"`,
+						},
+						"limit": {
+							Type:        genai.TypeInteger,
+							Description: "Max results (default: 10)",
+						},
+					},
+					Required: []string{"query"},
+				},
+			},
+		},
+	}
+}
+
+// Execute runs the tool with the given function call
+func (f *findAlerts) Execute(ctx context.Context, fc genai.FunctionCall) (*genai.FunctionResponse, error) {
+	paramsJSON, err := json.Marshal(fc.Args)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal function arguments")
+	}
+
+	var input findAlertsInput
+	if err := json.Unmarshal(paramsJSON, &input); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse input parameters")
+	}
+
+	fmt.Printf("🔍 アラート検索中: %s\n", input.Query)
+
+	results, err := f.uc.Find(ctx, usecase.FindOptions{
+		Query: input.Query,
+		Limit: input.Limit,
+	})
+	if err != nil {
+		fmt.Printf("❌ アラート検索エラー: %v\n", err)
+		return nil, goerr.Wrap(err, "failed to find alerts")
+	}
+
+	return &genai.FunctionResponse{
+		Name:     fc.Name,
+		Response: map[string]any{"result": formatFindResults(results)},
+	}, nil
+}
+
+// formatFindResults formats the results with their relevance
+func formatFindResults(results []*usecase.FindResult) string {
+	if len(results) == 0 {
+		return "No alerts found matching the query."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d alert(s), most relevant first:\n\n", len(results))
+	for i, r := range results {
+		fmt.Fprintf(&b, "%d. ID: %s\n", i+1, r.Alert.ID)
+		fmt.Fprintf(&b, "   Title: %s\n", r.Alert.Title)
+		fmt.Fprintf(&b, "   Created: %s\n", r.Alert.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(&b, "   Score: %.4f\n", r.Score)
+		if r.Distance != nil {
+			fmt.Fprintf(&b, "   Distance: %.4f\n", *r.Distance)
+		}
+		fmt.Fprintf(&b, "   Matched by: %s\n", strings.Join(r.MatchedBy, ", "))
+		if r.Alert.Description != "" {
+			fmt.Fprintf(&b, "   Description: %s\n", r.Alert.Description)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
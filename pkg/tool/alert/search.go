@@ -21,6 +21,7 @@ type searchAlertsInput struct {
 	ValueType string `json:"value_type"`
 	Limit     int    `json:"limit"`
 	Offset    int    `json:"offset"`
+	PageToken string `json:"page_token"`
 }
 
 type searchAlerts struct {
@@ -84,7 +85,11 @@ func (s *searchAlerts) Spec() *genai.Tool {
 						},
 						"offset": {
 							Type:        genai.TypeInteger,
-							Description: "Skip count for pagination (default: 0)",
+							Description: "Skip count for pagination (default: 0). Prefer page_token for next pages",
+						},
+						"page_token": {
+							Type:        genai.TypeString,
+							Description: "Token to get the next page, returned as next_page_token by the previous search with the same conditions",
 						},
 					},
 					Required: []string{"field", "operator", "value"},
@@ -122,23 +127,27 @@ func (s *searchAlerts) Execute(ctx context.Context, fc genai.FunctionCall) (*gen
 	fmt.Printf("🔍 アラート検索中: %s %s %v\n", input.Field, input.Operator, input.Value)
 
 	// Search via repository
-	alerts, err := s.repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
-		Field:    input.Field,
-		Operator: input.Operator,
-		Value:    convertedValue,
-		Limit:    input.Limit,
-		Offset:   input.Offset,
+	alerts, nextPageToken, err := s.repo.SearchAlerts(ctx, &repository.SearchAlertsInput{
+		Field:     input.Field,
+		Operator:  input.Operator,
+		Value:     convertedValue,
+		Limit:     input.Limit,
+		Offset:    input.Offset,
+		PageToken: input.PageToken,
 	})
 	if err != nil {
 		fmt.Printf("❌ アラート検索エラー: %v\n", err)
 		return nil, goerr.Wrap(err, "failed to search alerts")
 	}
 
-	result := formatResult(alerts)
+	response := map[string]any{"result": formatResult(alerts)}
+	if nextPageToken != "" {
+		response["next_page_token"] = nextPageToken
+	}
 
 	return &genai.FunctionResponse{
 		Name:     fc.Name,
-		Response: map[string]any{"result": result},
+		Response: response,
 	}, nil
 }
 
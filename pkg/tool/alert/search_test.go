@@ -33,6 +33,7 @@ func TestSearchAlertsSchema(t *testing.T) {
 	gt.Map(t, schema.Properties).HasKey("value")
 	gt.Map(t, schema.Properties).HasKey("limit")
 	gt.Map(t, schema.Properties).HasKey("offset")
+	gt.Map(t, schema.Properties).HasKey("page_token")
 
 	// Check required fields
 	gt.Equal(t, len(schema.Required), 3)
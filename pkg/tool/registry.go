@@ -2,6 +2,7 @@ package tool
 
 import (
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/m-mizutani/goerr/v2"
@@ -123,24 +124,29 @@ func (r *Registry) Init(ctx context.Context, client *Client) error {
 	return nil
 }
 
-// EnabledTools returns the list of enabled tool names
+// EnabledTools returns the list of enabled tool names sorted by name
 func (r *Registry) EnabledTools() []string {
 	tools := make([]string, 0, len(r.tools))
 	for name := range r.tools {
 		tools = append(tools, name)
 	}
+	slices.Sort(tools)
 	return tools
 }
 
-// Tools returns all enabled tools
+// Tools returns all enabled tools in the order given to New
 func (r *Registry) Tools() []Tool {
 	// Return unique tools (dedup by pointer)
 	seen := make(map[Tool]bool)
-	result := make([]Tool, 0, len(r.tools))
 	for _, t := range r.tools {
-		if !seen[t] {
-			seen[t] = true
+		seen[t] = true
+	}
+
+	result := make([]Tool, 0, len(seen))
+	for _, t := range r.allTools {
+		if seen[t] {
 			result = append(result, t)
+			delete(seen, t)
 		}
 	}
 	return result
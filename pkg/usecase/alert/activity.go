@@ -0,0 +1,66 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+)
+
+// record appends activities to the alert timeline. nil activities are skipped.
+func (u *UseCase) record(ctx context.Context, activities ...*model.Activity) error {
+	for _, activity := range activities {
+		if activity == nil {
+			continue
+		}
+		if err := u.repo.AddActivity(ctx, activity); err != nil {
+			return goerr.Wrap(err, "failed to record activity", goerr.V("alertID", activity.AlertID), goerr.V("type", activity.Type))
+		}
+	}
+	return nil
+}
+
+// newActivity creates an activity by the actor of the UseCase
+func (u *UseCase) newActivity(alertID model.AlertID, activityType model.ActivityType) *model.Activity {
+	return model.NewActivity(alertID, activityType, u.actor)
+}
+
+// statusChanged creates an activity of status change, or nil if not changed
+func (u *UseCase) statusChanged(alert *model.Alert, from model.AlertStatus) *model.Activity {
+	if from == alert.Status {
+		return nil
+	}
+	activity := u.newActivity(alert.ID, model.ActivityStatusChanged)
+	activity.From = string(from)
+	activity.To = string(alert.Status)
+	return activity
+}
+
+// Timeline retrieves activities of an alert in chronological order
+func (u *UseCase) Timeline(ctx context.Context, alertID model.AlertID) ([]*model.Activity, error) {
+	if _, err := u.repo.GetAlert(ctx, alertID); err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert", goerr.Value("alertID", alertID))
+	}
+
+	activities, err := u.repo.ListActivitiesByAlert(ctx, alertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list activities", goerr.Value("alertID", alertID))
+	}
+
+	return activities, nil
+}
+
+// AddNote appends a free-form note of an analyst to the alert timeline
+func (u *UseCase) AddNote(ctx context.Context, alertID model.AlertID, note string) error {
+	if note == "" {
+		return goerr.New("note is empty")
+	}
+
+	if _, err := u.repo.GetAlert(ctx, alertID); err != nil {
+		return goerr.Wrap(err, "failed to get alert", goerr.Value("alertID", alertID))
+	}
+
+	activity := u.newActivity(alertID, model.ActivityNoteAdded)
+	activity.Note = note
+	return u.record(ctx, activity)
+}
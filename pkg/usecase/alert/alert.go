@@ -13,6 +13,9 @@ type UseCase struct {
 	repo   repository.Repository
 	gemini adapter.Gemini
 	output io.Writer
+	actor  string
+
+	grouping *GroupConfig
 }
 
 // Option is a functional option for UseCase
@@ -25,6 +28,13 @@ func WithOutput(w io.Writer) Option {
 	}
 }
 
+// WithActor sets the analyst recorded in the alert timeline
+func WithActor(actor string) Option {
+	return func(uc *UseCase) {
+		uc.actor = actor
+	}
+}
+
 // New creates a new alert UseCase instance
 func New(
 	repo repository.Repository,
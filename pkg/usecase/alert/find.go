@@ -0,0 +1,224 @@
+package alert
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+)
+
+// Matchers of Find, recorded in FindResult.MatchedBy
+const (
+	MatchSemantic  = "semantic"
+	MatchKeyword   = "keyword"
+	MatchAttribute = "attribute"
+)
+
+const (
+	// rrfK is the constant of reciprocal rank fusion. Larger value makes
+	// difference of ranks smaller.
+	rrfK = 60
+
+	// findCandidates is the max number of candidates from each matcher
+	findCandidates = 50
+
+	// findMaxTerms is the max number of query terms used for keyword and
+	// attribute matching to bound the number of queries
+	findMaxTerms = 8
+)
+
+// FindOptions contains options for hybrid search of alerts
+type FindOptions struct {
+	Query     string
+	Limit     int     // Max results (default: 10)
+	Threshold float64 // Max cosine distance of semantic matching (default: 1.0)
+}
+
+// FindResult is an alert found by Find with its ranking information
+type FindResult struct {
+	Alert *model.Alert
+
+	// Score is the fused score of all matchers. Higher is more relevant.
+	Score float64
+
+	// Distance is the cosine distance between query and alert. It is nil if
+	// the alert has no embedding.
+	Distance *float64
+
+	MatchedBy []string
+}
+
+// Find searches alerts by natural language query. It combines semantic
+// search by embedding, title keyword and attribute matching, and ranks the
+// merged results by reciprocal rank fusion.
+func (u *UseCase) Find(ctx context.Context, opts FindOptions) ([]*FindResult, error) {
+	if strings.TrimSpace(opts.Query) == "" {
+		return nil, goerr.New("query is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = 1.0
+	}
+
+	embedding, err := u.gemini.Embedding(ctx, opts.Query, 768)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to generate embedding of query")
+	}
+
+	results := make(map[model.AlertID]*FindResult)
+	fuse := func(matcher string, ranked []*model.Alert) {
+		for rank, alert := range ranked {
+			r, ok := results[alert.ID]
+			if !ok {
+				r = &FindResult{Alert: alert}
+				if d, ok := repository.CosineDistance(embedding, alert.Embedding); ok {
+					r.Distance = &d
+				}
+				results[alert.ID] = r
+			}
+			r.Score += 1.0 / float64(rrfK+rank+1)
+			r.MatchedBy = append(r.MatchedBy, matcher)
+		}
+	}
+
+	semantic, err := u.repo.SearchSimilarAlerts(ctx, embedding, opts.Threshold)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to search similar alerts")
+	}
+	if len(semantic) > findCandidates {
+		semantic = semantic[:findCandidates]
+	}
+	fuse(MatchSemantic, semantic)
+
+	keyword, err := u.findByKeywords(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	fuse(MatchKeyword, keyword)
+
+	attribute, err := u.findByAttributes(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	fuse(MatchAttribute, attribute)
+
+	found := make([]*FindResult, 0, len(results))
+	for _, r := range results {
+		found = append(found, r)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		return distanceOf(found[i]) < distanceOf(found[j])
+	})
+
+	if len(found) > opts.Limit {
+		found = found[:opts.Limit]
+	}
+	return found, nil
+}
+
+// findByKeywords returns alerts having words of query in their title, ranked
+// by the number of matched words
+func (u *UseCase) findByKeywords(ctx context.Context, query string) ([]*model.Alert, error) {
+	counts := make(map[model.AlertID]int)
+	alerts := make(map[model.AlertID]*model.Alert)
+
+	for _, word := range queryTerms(model.SplitWords(query)) {
+		matched, _, err := u.repo.ListAlerts(ctx, &repository.ListAlertsInput{
+			TitleKeyword: word,
+			Limit:        findCandidates,
+		})
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list alerts by keyword", goerr.V("keyword", word))
+		}
+		for _, alert := range matched {
+			counts[alert.ID]++
+			alerts[alert.ID] = alert
+		}
+	}
+
+	return rankByCount(alerts, counts), nil
+}
+
+// findByAttributes returns alerts having terms of query as indicators in
+// their attributes, ranked by the number of matched terms
+func (u *UseCase) findByAttributes(ctx context.Context, query string) ([]*model.Alert, error) {
+	counts := make(map[model.AlertID]int)
+	alerts := make(map[model.AlertID]*model.Alert)
+
+	// Indicators such as IP address and URL contain punctuation, so query is
+	// split only by space and quotes around them are removed
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if term := strings.Trim(field, `"'()[]{}<>,;`); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	for _, term := range queryTerms(terms) {
+		matched, _, err := u.repo.SearchAlertsByAttribute(ctx, &repository.SearchAttributesInput{
+			Value: term,
+			Limit: findCandidates,
+		})
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to search alerts by attribute", goerr.V("value", term))
+		}
+		for _, alert := range matched {
+			counts[alert.ID]++
+			alerts[alert.ID] = alert
+		}
+	}
+
+	return rankByCount(alerts, counts), nil
+}
+
+// queryTerms removes duplicated terms and limits the number of them
+func queryTerms(terms []string) []string {
+	var unique []string
+	for _, term := range terms {
+		if !slices.Contains(unique, term) {
+			unique = append(unique, term)
+		}
+	}
+	if len(unique) > findMaxTerms {
+		unique = unique[:findMaxTerms]
+	}
+	return unique
+}
+
+// rankByCount orders alerts by count in descending order, and newer first
+// for the same count
+func rankByCount(alerts map[model.AlertID]*model.Alert, counts map[model.AlertID]int) []*model.Alert {
+	ranked := make([]*model.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		ranked = append(ranked, alert)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if counts[a.ID] != counts[b.ID] {
+			return counts[a.ID] > counts[b.ID]
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return ranked
+}
+
+// distanceOf returns distance of the result, or the max cosine distance if
+// it is unknown
+func distanceOf(r *FindResult) float64 {
+	if r.Distance == nil {
+		return 2.0
+	}
+	return *r.Distance
+}
@@ -0,0 +1,102 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"google.golang.org/genai"
+)
+
+// embeddingGemini returns a fixed embedding for any text
+type embeddingGemini struct {
+	embedding firestore.Vector32
+}
+
+func (m *embeddingGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *embeddingGemini) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		yield(nil, errors.New("not implemented"))
+	}
+}
+
+func (m *embeddingGemini) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *embeddingGemini) Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error) {
+	return m.embedding, nil
+}
+
+func TestFind(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	now := time.Now()
+	semantic := &model.Alert{
+		ID:        model.NewAlertID(),
+		Title:     "Unusual API call",
+		Embedding: firestore.Vector32{1, 0},
+		CreatedAt: now,
+	}
+	both := &model.Alert{
+		ID:        model.NewAlertID(),
+		Title:     "Brute force login",
+		Embedding: firestore.Vector32{0.9, 0.1},
+		Attributes: []*model.Attribute{
+			{Key: "src_ip", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress},
+		},
+		CreatedAt: now.Add(-time.Hour),
+	}
+	keywordOnly := &model.Alert{
+		ID:        model.NewAlertID(),
+		Title:     "Login from new device",
+		Embedding: firestore.Vector32{0, 1},
+		CreatedAt: now.Add(-2 * time.Hour),
+	}
+	for _, a := range []*model.Alert{semantic, both, keywordOnly} {
+		gt.NoError(t, repo.PutAlert(ctx, a))
+	}
+
+	uc := alert.New(repo, &embeddingGemini{embedding: firestore.Vector32{1, 0}})
+
+	results, err := uc.Find(ctx, alert.FindOptions{
+		Query:     "login from 192.0.2.1",
+		Threshold: 0.5,
+	})
+	gt.NoError(t, err)
+	gt.A(t, results).Length(3)
+
+	// Matched by all of semantic, keyword and attribute
+	gt.Equal(t, results[0].Alert.ID, both.ID)
+	gt.A(t, results[0].MatchedBy).Length(3)
+	gt.V(t, results[0].Distance).NotNil()
+
+	// Semantic match is out of threshold, but distance is still shown
+	last := results[len(results)-1]
+	gt.Equal(t, last.Alert.ID, keywordOnly.ID)
+	gt.Equal(t, last.MatchedBy, []string{alert.MatchKeyword})
+	gt.True(t, *last.Distance > 0.5)
+
+	t.Run("limit", func(t *testing.T) {
+		results, err := uc.Find(ctx, alert.FindOptions{Query: "login", Limit: 1})
+		gt.NoError(t, err)
+		gt.A(t, results).Length(1)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		_, err := uc.Find(ctx, alert.FindOptions{Query: " "})
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,210 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+)
+
+// maxGroupAttributes bounds the number of attribute queries to find
+// candidates for group hooks
+const maxGroupAttributes = 8
+
+// GroupAction is how a new alert is grouped into a matched open alert
+type GroupAction string
+
+const (
+	// GroupActionMerge merges the new alert into the matched alert
+	GroupActionMerge GroupAction = "merge"
+
+	// GroupActionIncident adds the new alert to the incident of the matched
+	// alert. An incident is created for the matched alert if it has none.
+	GroupActionIncident GroupAction = "incident"
+)
+
+// Validate checks if the action is valid
+func (a GroupAction) Validate() error {
+	switch a {
+	case GroupActionMerge, GroupActionIncident:
+		return nil
+	default:
+		return goerr.New("invalid group action", goerr.V("action", a))
+	}
+}
+
+// GroupConfig configures automatic grouping of new alerts into existing open
+// alerts. A new alert is grouped if an open alert is within Threshold of
+// cosine distance, or if any hook decides so.
+type GroupConfig struct {
+	// Action is how to group the new alert. Default is GroupActionMerge.
+	Action GroupAction
+
+	// Threshold is the max cosine distance to group by similarity. Zero
+	// disables similarity matching and only hooks decide.
+	Threshold float64
+
+	// Window limits candidates to alerts created within the duration before
+	// the new alert. Zero means no limit.
+	Window time.Duration
+}
+
+// GroupCandidate is an existing open alert that a new alert may be grouped
+// into
+type GroupCandidate struct {
+	Alert    *model.Alert
+	Distance float64 // Cosine distance from the new alert, 2.0 if unknown
+}
+
+// GroupHook decides if the new alert should be grouped into the candidate,
+// e.g. by policy. Candidates are given in ascending order of distance and the
+// first one accepted is used.
+type GroupHook func(ctx context.Context, alert *model.Alert, candidate *GroupCandidate) (bool, error)
+
+// WithGrouping enables automatic grouping on Insert and InsertIngested
+func WithGrouping(cfg GroupConfig) Option {
+	return func(uc *UseCase) {
+		uc.grouping = &cfg
+	}
+}
+
+// WithGroupHook adds a hook to decide grouping of the inserted alert. It
+// enables grouping even if WithGrouping is not set.
+func WithGroupHook(hook GroupHook) InsertOption {
+	return func(o *insertOptions) {
+		o.groupHooks = append(o.groupHooks, hook)
+	}
+}
+
+// findGroup returns the open alert that the new alert should be grouped
+// into, or nil if there is none
+func (u *UseCase) findGroup(ctx context.Context, alert *model.Alert, hooks []GroupHook) (*GroupCandidate, error) {
+	var cfg GroupConfig
+	if u.grouping != nil {
+		cfg = *u.grouping
+	}
+	if cfg.Threshold <= 0 && len(hooks) == 0 {
+		return nil, nil
+	}
+
+	found := make(map[model.AlertID]*model.Alert)
+	if cfg.Threshold > 0 {
+		similar, err := u.repo.SearchSimilarAlerts(ctx, alert.Embedding, cfg.Threshold)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to search similar alerts for grouping")
+		}
+		for _, a := range similar {
+			found[a.ID] = a
+		}
+	}
+
+	// Hooks may match alerts that are not similar as a whole, e.g. having
+	// the same indicators, so alerts sharing attributes are also candidates
+	if len(hooks) > 0 {
+		for i, attr := range alert.Attributes {
+			if i >= maxGroupAttributes {
+				break
+			}
+			shared, _, err := u.repo.SearchAlertsByAttribute(ctx, &repository.SearchAttributesInput{
+				Type:  attr.Type,
+				Value: attr.Value,
+				Limit: 100,
+			})
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to search alerts by attribute for grouping")
+			}
+			for _, a := range shared {
+				found[a.ID] = a
+			}
+		}
+	}
+
+	var candidates []*GroupCandidate
+	for _, a := range found {
+		if a.ID == alert.ID || a.MergedTo != "" || a.CurrentStatus().Done() {
+			continue
+		}
+		if cfg.Window > 0 && alert.CreatedAt.Sub(a.CreatedAt) > cfg.Window {
+			continue
+		}
+
+		candidate := &GroupCandidate{Alert: a, Distance: 2.0}
+		if d, ok := repository.CosineDistance(alert.Embedding, a.Embedding); ok {
+			candidate.Distance = d
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		return candidates[i].Alert.CreatedAt.Before(candidates[j].Alert.CreatedAt)
+	})
+
+	for _, candidate := range candidates {
+		if cfg.Threshold > 0 && candidate.Distance <= cfg.Threshold {
+			return candidate, nil
+		}
+		for _, hook := range hooks {
+			matched, err := hook(ctx, alert, candidate)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to run group hook", goerr.V("candidate", candidate.Alert.ID))
+			}
+			if matched {
+				return candidate, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// groupAction returns the configured group action
+func (u *UseCase) groupAction() GroupAction {
+	if u.grouping == nil || u.grouping.Action == "" {
+		return GroupActionMerge
+	}
+	return u.grouping.Action
+}
+
+// attachToIncident adds the saved alert to the incident of the matched alert,
+// or creates a new incident of both alerts
+func (u *UseCase) attachToIncident(ctx context.Context, alert *model.Alert, group *GroupCandidate) error {
+	incidents, err := u.repo.ListIncidentsByAlert(ctx, group.Alert.ID)
+	if err != nil {
+		return goerr.Wrap(err, "failed to get incidents of grouped alert", goerr.V("alertID", group.Alert.ID))
+	}
+
+	if len(incidents) > 0 {
+		if _, err := u.AddToIncident(ctx, incidents[0].ID, []model.AlertID{alert.ID}); err != nil {
+			return goerr.Wrap(err, "failed to add alert to incident", goerr.V("incidentID", incidents[0].ID))
+		}
+		return nil
+	}
+
+	description := fmt.Sprintf("Created automatically by grouping of similar alerts (distance: %.4f)", group.Distance)
+	if _, err := u.CreateIncident(ctx, group.Alert.Title, description, []model.AlertID{group.Alert.ID, alert.ID}); err != nil {
+		return goerr.Wrap(err, "failed to create incident for grouped alerts")
+	}
+	return nil
+}
+
+// groupActivities returns activities of automatic merge of alert into group
+func groupActivities(alert *model.Alert, group *GroupCandidate) []*model.Activity {
+	note := fmt.Sprintf("grouped automatically (distance: %.4f)", group.Distance)
+
+	// Grouping is done by ingestion, not by an analyst
+	mergedInto := model.NewActivity(alert.ID, model.ActivityMergedInto, "")
+	mergedInto.RelatedID = string(group.Alert.ID)
+	mergedInto.Note = note
+	mergedFrom := model.NewActivity(group.Alert.ID, model.ActivityMergedFrom, "")
+	mergedFrom.RelatedID = string(alert.ID)
+	mergedFrom.Note = note
+
+	return []*model.Activity{mergedInto, mergedFrom}
+}
@@ -0,0 +1,137 @@
+package alert_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+)
+
+func TestInsertGrouping(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Now()
+	open := &model.Alert{
+		ID:        model.NewAlertID(),
+		Title:     "Brute force login",
+		Embedding: firestore.Vector32{1, 0},
+		Attributes: []*model.Attribute{
+			{Key: "src_ip", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress},
+		},
+		Status:    model.AlertStatusInvestigating,
+		CreatedAt: now.Add(-time.Hour),
+	}
+	resolved := &model.Alert{
+		ID:        model.NewAlertID(),
+		Title:     "Brute force login",
+		Embedding: firestore.Vector32{1, 0},
+		Status:    model.AlertStatusResolved,
+		CreatedAt: now.Add(-time.Minute),
+	}
+
+	// Each subtest has its own repository so that inserted alerts do not
+	// become candidates of other subtests
+	setup := func(t *testing.T) repository.Repository {
+		t.Helper()
+		repo, err := repository.NewLocal(t.TempDir())
+		gt.NoError(t, err)
+		for _, a := range []*model.Alert{open, resolved} {
+			saved := *a
+			gt.NoError(t, repo.PutAlert(ctx, &saved))
+		}
+		return repo
+	}
+
+	gemini := &embeddingGemini{embedding: firestore.Vector32{0.99, 0.01}}
+	input := &model.Alert{
+		Title:       "Brute force login",
+		Description: "Many failed logins",
+		Data:        map[string]any{"user": "alice"},
+	}
+
+	t.Run("grouped into similar open alert", func(t *testing.T) {
+		repo := setup(t)
+		uc := alert.New(repo, gemini, alert.WithGrouping(alert.GroupConfig{
+			Threshold: 0.1,
+			Window:    24 * time.Hour,
+		}))
+		newAlert, err := uc.InsertIngested(ctx, input)
+		gt.NoError(t, err)
+		gt.Equal(t, newAlert.MergedTo, open.ID)
+
+		activities, err := repo.ListActivitiesByAlert(ctx, open.ID)
+		gt.NoError(t, err)
+		gt.A(t, activities).Length(1).Required()
+		gt.Equal(t, activities[0].Type, model.ActivityMergedFrom)
+		gt.Equal(t, activities[0].RelatedID, string(newAlert.ID))
+	})
+
+	t.Run("out of window", func(t *testing.T) {
+		repo := setup(t)
+		uc := alert.New(repo, gemini, alert.WithGrouping(alert.GroupConfig{
+			Threshold: 0.1,
+			Window:    time.Minute,
+		}))
+		newAlert, err := uc.InsertIngested(ctx, input)
+		gt.NoError(t, err)
+		gt.Equal(t, newAlert.MergedTo, "")
+	})
+
+	t.Run("hook decides grouping", func(t *testing.T) {
+		repo := setup(t)
+		// Not similar, but shares an indicator with the open alert
+		uc := alert.New(repo, &embeddingGemini{embedding: firestore.Vector32{0, 1}})
+
+		var candidates []model.AlertID
+		newAlert, err := uc.InsertIngested(ctx, &model.Alert{
+			Title: "Login from new device",
+			Data:  map[string]any{"user": "bob"},
+			Attributes: []*model.Attribute{
+				{Key: "remote_addr", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress},
+			},
+		}, alert.WithGroupHook(func(ctx context.Context, a *model.Alert, c *alert.GroupCandidate) (bool, error) {
+			candidates = append(candidates, c.Alert.ID)
+			return true, nil
+		}))
+		gt.NoError(t, err)
+		gt.Equal(t, candidates, []model.AlertID{open.ID})
+		gt.Equal(t, newAlert.MergedTo, open.ID)
+	})
+
+	t.Run("attach to incident", func(t *testing.T) {
+		repo := setup(t)
+		uc := alert.New(repo, gemini, alert.WithGrouping(alert.GroupConfig{
+			Action:    alert.GroupActionIncident,
+			Threshold: 0.1,
+		}))
+
+		first, err := uc.InsertIngested(ctx, input)
+		gt.NoError(t, err)
+		gt.Equal(t, first.MergedTo, "")
+
+		incidents, err := repo.ListIncidentsByAlert(ctx, open.ID)
+		gt.NoError(t, err)
+		gt.A(t, incidents).Length(1).Required()
+		gt.Equal(t, incidents[0].AlertIDs, []model.AlertID{open.ID, first.ID})
+
+		// Next alert joins the existing incident
+		second, err := uc.InsertIngested(ctx, input)
+		gt.NoError(t, err)
+		incident, err := repo.GetIncident(ctx, incidents[0].ID)
+		gt.NoError(t, err)
+		gt.True(t, incident.HasAlert(second.ID))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		repo := setup(t)
+		uc := alert.New(repo, gemini)
+		newAlert, err := uc.InsertIngested(ctx, input)
+		gt.NoError(t, err)
+		gt.Equal(t, newAlert.MergedTo, "")
+	})
+}
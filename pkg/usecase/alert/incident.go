@@ -0,0 +1,247 @@
+package alert
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+)
+
+var (
+	// ErrAlreadyInIncident is returned when an alert to add already belongs to another incident
+	ErrAlreadyInIncident = goerr.New("alert already belongs to another incident")
+)
+
+// CreateIncident creates a new incident having the alerts as members
+func (u *UseCase) CreateIncident(
+	ctx context.Context,
+	title, description string,
+	alertIDs []model.AlertID,
+) (*model.Incident, error) {
+	if title == "" {
+		return nil, goerr.New("incident title is required")
+	}
+
+	now := time.Now()
+	incident := &model.Incident{
+		ID:          model.NewIncidentID(),
+		Title:       title,
+		Description: description,
+		Status:      model.AlertStatusNew,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	created := model.NewIncidentActivity(incident.ID, model.ActivityCreated, u.actor)
+	created.Note = incident.Title
+
+	added, err := u.addAlerts(ctx, incident, alertIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.PutIncident(ctx, incident); err != nil {
+		return nil, goerr.Wrap(err, "failed to save incident", goerr.V("incidentID", incident.ID))
+	}
+
+	if err := u.record(ctx, append([]*model.Activity{created}, added...)...); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// AddToIncident adds alerts to an incident. Alerts already in the incident
+// are ignored.
+func (u *UseCase) AddToIncident(
+	ctx context.Context,
+	incidentID model.IncidentID,
+	alertIDs []model.AlertID,
+) (*model.Incident, error) {
+	incident, err := u.repo.GetIncident(ctx, incidentID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get incident", goerr.V("incidentID", incidentID))
+	}
+
+	activities, err := u.addAlerts(ctx, incident, alertIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		return incident, nil
+	}
+
+	incident.UpdatedAt = time.Now()
+	if err := u.repo.PutIncident(ctx, incident); err != nil {
+		return nil, goerr.Wrap(err, "failed to update incident", goerr.V("incidentID", incidentID))
+	}
+
+	if err := u.record(ctx, activities...); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// addAlerts appends alerts to members of the incident and returns activities
+// to record after the incident is saved
+func (u *UseCase) addAlerts(ctx context.Context, incident *model.Incident, alertIDs []model.AlertID) ([]*model.Activity, error) {
+	var activities []*model.Activity
+	for _, alertID := range alertIDs {
+		if incident.HasAlert(alertID) {
+			continue
+		}
+
+		if _, err := u.repo.GetAlert(ctx, alertID); err != nil {
+			return nil, goerr.Wrap(err, "failed to get alert", goerr.V("alertID", alertID))
+		}
+
+		incidents, err := u.repo.ListIncidentsByAlert(ctx, alertID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get incidents of alert", goerr.V("alertID", alertID))
+		}
+		for _, other := range incidents {
+			if other.ID != incident.ID {
+				return nil, goerr.Wrap(ErrAlreadyInIncident, "remove the alert from the incident first",
+					goerr.V("alertID", alertID),
+					goerr.V("incidentID", other.ID),
+				)
+			}
+		}
+
+		incident.AlertIDs = append(incident.AlertIDs, alertID)
+
+		added := u.newActivity(alertID, model.ActivityIncidentAdded)
+		added.IncidentID = incident.ID
+		activities = append(activities, added)
+	}
+
+	return activities, nil
+}
+
+// RemoveFromIncident removes alerts from an incident
+func (u *UseCase) RemoveFromIncident(
+	ctx context.Context,
+	incidentID model.IncidentID,
+	alertIDs []model.AlertID,
+) (*model.Incident, error) {
+	incident, err := u.repo.GetIncident(ctx, incidentID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get incident", goerr.V("incidentID", incidentID))
+	}
+
+	var activities []*model.Activity
+	for _, alertID := range alertIDs {
+		if !incident.HasAlert(alertID) {
+			return nil, goerr.New("alert is not a member of the incident",
+				goerr.V("alertID", alertID),
+				goerr.V("incidentID", incidentID),
+			)
+		}
+		incident.AlertIDs = slices.DeleteFunc(incident.AlertIDs, func(id model.AlertID) bool {
+			return id == alertID
+		})
+
+		removed := u.newActivity(alertID, model.ActivityIncidentRemoved)
+		removed.IncidentID = incident.ID
+		activities = append(activities, removed)
+	}
+
+	incident.UpdatedAt = time.Now()
+	if err := u.repo.PutIncident(ctx, incident); err != nil {
+		return nil, goerr.Wrap(err, "failed to update incident", goerr.V("incidentID", incidentID))
+	}
+
+	if err := u.record(ctx, activities...); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// SetIncidentStatus moves an incident to the status. Legal moves are the
+// same as alerts.
+func (u *UseCase) SetIncidentStatus(
+	ctx context.Context,
+	incidentID model.IncidentID,
+	status model.AlertStatus,
+	note string,
+) (*model.Incident, error) {
+	if err := status.Validate(); err != nil {
+		return nil, err
+	}
+
+	incident, err := u.repo.GetIncident(ctx, incidentID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get incident", goerr.V("incidentID", incidentID))
+	}
+
+	from := incident.CurrentStatus()
+	if !slices.Contains(transitions[from], status) {
+		return nil, goerr.Wrap(ErrInvalidTransition, "incident can not move to the status",
+			goerr.V("incidentID", incidentID),
+			goerr.V("from", from),
+			goerr.V("to", status),
+		)
+	}
+
+	incident.Status = status
+	incident.UpdatedAt = time.Now()
+	if err := u.repo.PutIncident(ctx, incident); err != nil {
+		return nil, goerr.Wrap(err, "failed to update incident", goerr.V("incidentID", incidentID))
+	}
+
+	activity := model.NewIncidentActivity(incident.ID, model.ActivityStatusChanged, u.actor)
+	activity.From = string(from)
+	activity.To = string(status)
+	activity.Note = note
+	if err := u.record(ctx, activity); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// ListIncidents retrieves incidents and a token of the next page
+func (u *UseCase) ListIncidents(
+	ctx context.Context,
+	input *repository.ListIncidentsInput,
+) ([]*model.Incident, string, error) {
+	if input.Status != nil {
+		if err := input.Status.Validate(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return u.repo.ListIncidents(ctx, input)
+}
+
+// ShowIncident retrieves an incident with its member alerts and timeline
+func (u *UseCase) ShowIncident(
+	ctx context.Context,
+	incidentID model.IncidentID,
+) (*model.Incident, []*model.Alert, []*model.Activity, error) {
+	incident, err := u.repo.GetIncident(ctx, incidentID)
+	if err != nil {
+		return nil, nil, nil, goerr.Wrap(err, "failed to get incident", goerr.V("incidentID", incidentID))
+	}
+
+	alerts := make([]*model.Alert, 0, len(incident.AlertIDs))
+	for _, alertID := range incident.AlertIDs {
+		a, err := u.repo.GetAlert(ctx, alertID)
+		if err != nil {
+			return nil, nil, nil, goerr.Wrap(err, "failed to get member alert", goerr.V("alertID", alertID))
+		}
+		alerts = append(alerts, a)
+	}
+
+	activities, err := u.repo.ListActivitiesByIncident(ctx, incidentID)
+	if err != nil {
+		return nil, nil, nil, goerr.Wrap(err, "failed to list activities", goerr.V("incidentID", incidentID))
+	}
+
+	return incident, alerts, activities, nil
+}
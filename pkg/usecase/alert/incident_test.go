@@ -0,0 +1,94 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+)
+
+func TestIncident(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	var alertIDs []model.AlertID
+	for range 3 {
+		a := &model.Alert{ID: model.NewAlertID(), Title: "Brute force login", CreatedAt: time.Now()}
+		gt.NoError(t, repo.PutAlert(ctx, a))
+		alertIDs = append(alertIDs, a.ID)
+	}
+
+	uc := alert.New(repo, nil, alert.WithActor("alice"))
+
+	incident, err := uc.CreateIncident(ctx, "Credential stuffing", "", alertIDs[:2])
+	gt.NoError(t, err)
+	gt.Equal(t, incident.AlertIDs, alertIDs[:2])
+	gt.Equal(t, incident.Status, model.AlertStatusNew)
+
+	t.Run("add", func(t *testing.T) {
+		// Existing member is ignored
+		incident, err := uc.AddToIncident(ctx, incident.ID, alertIDs[1:])
+		gt.NoError(t, err)
+		gt.Equal(t, incident.AlertIDs, alertIDs)
+
+		// Addition appears in the alert timeline, too
+		activities, err := uc.Timeline(ctx, alertIDs[2])
+		gt.NoError(t, err)
+		gt.A(t, activities).Length(1)
+		gt.Equal(t, activities[0].Type, model.ActivityIncidentAdded)
+		gt.Equal(t, activities[0].IncidentID, incident.ID)
+	})
+
+	t.Run("alert belongs to one incident", func(t *testing.T) {
+		_, err := uc.CreateIncident(ctx, "Another", "", alertIDs[:1])
+		gt.True(t, errors.Is(err, alert.ErrAlreadyInIncident))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		incident, err := uc.RemoveFromIncident(ctx, incident.ID, alertIDs[:1])
+		gt.NoError(t, err)
+		gt.Equal(t, incident.AlertIDs, alertIDs[1:])
+
+		_, err = uc.RemoveFromIncident(ctx, incident.ID, alertIDs[:1])
+		gt.Error(t, err)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		updated, err := uc.SetIncidentStatus(ctx, incident.ID, model.AlertStatusInvestigating, "")
+		gt.NoError(t, err)
+		gt.Equal(t, updated.Status, model.AlertStatusInvestigating)
+
+		_, err = uc.SetIncidentStatus(ctx, incident.ID, model.AlertStatusAcknowledged, "")
+		gt.True(t, errors.Is(err, alert.ErrInvalidTransition))
+	})
+
+	t.Run("show", func(t *testing.T) {
+		got, alerts, activities, err := uc.ShowIncident(ctx, incident.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, got.ID, incident.ID)
+		gt.A(t, alerts).Length(2)
+
+		// created, 2 added, 1 added, 1 removed and status changed
+		gt.A(t, activities).Length(6)
+		gt.Equal(t, activities[0].Type, model.ActivityCreated)
+		gt.Equal(t, activities[0].Actor, "alice")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		status := model.AlertStatusInvestigating
+		incidents, _, err := uc.ListIncidents(ctx, &repository.ListIncidentsInput{Status: &status})
+		gt.NoError(t, err)
+		gt.A(t, incidents).Length(1)
+
+		status = model.AlertStatusClosed
+		incidents, _, err = uc.ListIncidents(ctx, &repository.ListIncidentsInput{Status: &status})
+		gt.NoError(t, err)
+		gt.A(t, incidents).Length(0)
+	})
+}
@@ -5,39 +5,102 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"text/template"
 	"time"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/leveret/pkg/adapter"
 	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
 	"github.com/m-mizutani/leveret/pkg/utils/logging"
 	"google.golang.org/genai"
 )
 
+// InsertOption is a functional option for Insert
+type InsertOption func(*insertOptions)
+
+type insertOptions struct {
+	id         model.AlertID
+	groupHooks []GroupHook
+}
+
+// WithAlertID makes Insert idempotent by using the given ID. If an alert with
+// the ID already exists, it is returned as is without generating a summary.
+func WithAlertID(id model.AlertID) InsertOption {
+	return func(o *insertOptions) {
+		o.id = id
+	}
+}
+
+// Insert saves raw alert data as a new alert. Title, description and
+// attributes are generated by LLM from the data.
 func (u *UseCase) Insert(
 	ctx context.Context,
 	data any,
+	opts ...InsertOption,
+) (*model.Alert, error) {
+	return u.insert(ctx, &model.Alert{Data: data}, true, opts)
+}
+
+// InsertIngested saves an alert produced by alert workflow. Title,
+// description, attributes and workflow results given by policies are stored
+// as they are, and only the embedding is generated. If the policy gives no
+// title, summary is generated by LLM to fill fields left empty.
+func (u *UseCase) InsertIngested(
+	ctx context.Context,
+	alert *model.Alert,
+	opts ...InsertOption,
 ) (*model.Alert, error) {
-	alert := &model.Alert{
-		ID:        model.NewAlertID(),
-		Data:      data,
-		CreatedAt: time.Now(),
+	newAlert := *alert
+	return u.insert(ctx, &newAlert, newAlert.Title == "", opts)
+}
+
+func (u *UseCase) insert(
+	ctx context.Context,
+	alert *model.Alert,
+	summarize bool,
+	opts []InsertOption,
+) (*model.Alert, error) {
+	var options insertOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	alert.ID = model.NewAlertID()
+	if options.id != "" {
+		// Checked in advance to skip LLM calls for known alerts
+		existing, err := u.repo.GetAlert(ctx, options.id)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, goerr.Wrap(err, "failed to check existing alert", goerr.V("id", options.id))
+		}
+		alert.ID = options.id
 	}
+	alert.Status = model.AlertStatusNew
+	alert.CreatedAt = time.Now()
 
-	jsonData, err := json.Marshal(data)
+	jsonData, err := json.Marshal(alert.Data)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to marshal alert data")
 	}
 
-	summary, err := generateSummary(ctx, u.gemini, string(jsonData))
-	if err != nil {
-		return nil, goerr.Wrap(err, "failed to generate summary")
+	if summarize {
+		summary, err := generateSummary(ctx, u.gemini, string(jsonData))
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to generate summary")
+		}
+		// Fields given by policies are kept
+		alert.Title = summary.Title
+		if alert.Description == "" {
+			alert.Description = summary.Description
+		}
+		if len(alert.Attributes) == 0 {
+			alert.Attributes = summary.Attributes
+		}
 	}
-	alert.Title = summary.Title
-	alert.Description = summary.Description
-	alert.Attributes = summary.Attributes
 
 	// Generate embedding vector from original alert data
 	embedding, err := u.gemini.Embedding(ctx, string(jsonData), 768)
@@ -46,10 +109,50 @@ func (u *UseCase) Insert(
 	}
 	alert.Embedding = embedding
 
-	if err := u.repo.PutAlert(ctx, alert); err != nil {
+	group, err := u.findGroup(ctx, alert, options.groupHooks)
+	if err != nil {
+		return nil, err
+	}
+	merge := group != nil && u.groupAction() == GroupActionMerge
+	if merge {
+		alert.MergedTo = group.Alert.ID
+	}
+
+	// Another insert with the same ID may have saved the alert after the
+	// check above. The winner is returned and records activities.
+	if err := u.repo.CreateAlert(ctx, alert); err != nil {
+		if options.id != "" && errors.Is(err, repository.ErrAlreadyExists) {
+			existing, err := u.repo.GetAlert(ctx, options.id)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to get existing alert", goerr.V("id", options.id))
+			}
+			return existing, nil
+		}
 		return nil, err
 	}
 
+	// Alerts are created by ingestion, not by an analyst
+	created := model.NewActivity(alert.ID, model.ActivityCreated, "")
+	var triaged *model.Activity
+	if alert.TriageAction != "" {
+		triaged = model.NewActivity(alert.ID, model.ActivityTriaged, "")
+		triaged.To = string(alert.TriageAction)
+		triaged.Note = alert.TriageNote
+	}
+	activities := []*model.Activity{created, triaged}
+	if merge {
+		activities = append(activities, groupActivities(alert, group)...)
+	}
+	if err := u.record(ctx, activities...); err != nil {
+		return nil, err
+	}
+
+	if group != nil && !merge {
+		if err := u.attachToIncident(ctx, alert, group); err != nil {
+			return nil, err
+		}
+	}
+
 	return alert, nil
 }
 
@@ -154,7 +257,7 @@ func generateSummary(ctx context.Context, gemini adapter.Gemini, alertData strin
 			},
 		}
 
-		resp, err := gemini.GenerateContent(ctx, contents, config)
+		resp, err := gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerSummary), contents, config)
 		if err != nil {
 			return nil, goerr.Wrap(err, "failed to generate content for summary")
 		}
@@ -170,11 +273,6 @@ func generateSummary(ctx context.Context, gemini adapter.Gemini, alertData strin
 			return nil, goerr.Wrap(err, "failed to unmarshal summary JSON", goerr.V("text", rawJSON))
 		}
 
-		// [DEBUG] Pretty print the parsed JSON
-		if prettyJSON, err := json.MarshalIndent(summary, "", "  "); err == nil {
-			fmt.Printf("parsed summary JSON: %s\n", string(prettyJSON))
-		}
-
 		if err := summary.validate(); err != nil {
 			logger.Warn("validation failed, retrying", "error", err, "title", summary.Title)
 			failedExamples = append(failedExamples, err.Error())
@@ -0,0 +1,86 @@
+package alert_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"google.golang.org/genai"
+)
+
+// summaryGemini returns a fixed summary and counts summary generation
+type summaryGemini struct {
+	embeddingGemini
+	called int
+}
+
+func (m *summaryGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	m.called++
+	text := `{"title": "Generated title", "description": "Generated description", "attributes": [{"key": "user", "value": "alice", "type": "string"}]}`
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{Content: &genai.Content{Parts: []*genai.Part{{Text: text}}}},
+		},
+	}, nil
+}
+
+func TestInsertIngested(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	t.Run("title given by policy", func(t *testing.T) {
+		gemini := &summaryGemini{embeddingGemini: embeddingGemini{embedding: firestore.Vector32{1, 0}}}
+		uc := alert.New(repo, gemini)
+
+		a, err := uc.InsertIngested(ctx, &model.Alert{
+			Title: "Policy title",
+			Data:  map[string]any{"user": "alice"},
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, a.Title, "Policy title")
+		gt.Equal(t, gemini.called, 0)
+	})
+
+	t.Run("no title given by policy", func(t *testing.T) {
+		gemini := &summaryGemini{embeddingGemini: embeddingGemini{embedding: firestore.Vector32{1, 0}}}
+		uc := alert.New(repo, gemini)
+
+		a, err := uc.InsertIngested(ctx, &model.Alert{
+			Description: "Policy description",
+			Data:        map[string]any{"user": "alice"},
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, gemini.called, 1)
+		gt.Equal(t, a.Title, "Generated title")
+		gt.Equal(t, a.Description, "Policy description")
+		gt.A(t, a.Attributes).Length(1)
+
+		saved, err := repo.GetAlert(ctx, a.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, saved.Title, "Generated title")
+	})
+
+	t.Run("existing alert ID", func(t *testing.T) {
+		gemini := &summaryGemini{embeddingGemini: embeddingGemini{embedding: firestore.Vector32{1, 0}}}
+		uc := alert.New(repo, gemini)
+
+		first, err := uc.InsertIngested(ctx, &model.Alert{Data: map[string]any{"user": "bob"}}, alert.WithAlertID("dedup-id"))
+		gt.NoError(t, err)
+		gt.Equal(t, first.ID, "dedup-id")
+		gt.Equal(t, gemini.called, 1)
+
+		second, err := uc.InsertIngested(ctx, &model.Alert{Data: map[string]any{"user": "bob"}}, alert.WithAlertID("dedup-id"))
+		gt.NoError(t, err)
+		gt.Equal(t, second.ID, first.ID)
+		gt.Equal(t, gemini.called, 1)
+
+		activities, err := repo.ListActivitiesByAlert(ctx, first.ID)
+		gt.NoError(t, err)
+		gt.A(t, activities).Length(1)
+	})
+}
@@ -4,35 +4,38 @@ import (
 	"context"
 
 	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
 )
 
-// ListOptions contains options for listing alerts
-type ListOptions struct {
-	IncludeMerged bool
-	Offset        int
-	Limit         int
-}
-
-// List retrieves a list of alerts
+// List retrieves a list of alerts matching the input and a token of the next
+// page. Filters are evaluated by the repository.
 func (u *UseCase) List(
 	ctx context.Context,
-	opts ListOptions,
-) ([]*model.Alert, error) {
-	alerts, err := u.repo.ListAlerts(ctx, opts.Offset, opts.Limit)
-	if err != nil {
-		return nil, err
+	input *repository.ListAlertsInput,
+) ([]*model.Alert, string, error) {
+	if err := validateFilter(input); err != nil {
+		return nil, "", err
 	}
 
-	// Filter out merged alerts if not included
-	if !opts.IncludeMerged {
-		filtered := make([]*model.Alert, 0, len(alerts))
-		for _, alert := range alerts {
-			if alert.MergedTo == "" {
-				filtered = append(filtered, alert)
-			}
+	return u.repo.ListAlerts(ctx, input)
+}
+
+// validateFilter checks values of filters that the repository does not know
+func validateFilter(input *repository.ListAlertsInput) error {
+	for _, status := range input.Statuses {
+		if err := status.Validate(); err != nil {
+			return err
 		}
-		return filtered, nil
 	}
-
-	return alerts, nil
+	if input.Severity != "" {
+		if err := input.Severity.Validate(); err != nil {
+			return err
+		}
+	}
+	if input.Conclusion != "" {
+		if err := input.Conclusion.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
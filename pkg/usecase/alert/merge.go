@@ -2,33 +2,209 @@ package alert
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
 )
 
-// Merge consolidates source alert into target alert
+var (
+	// ErrSelfMerge is returned when an alert is merged into itself
+	ErrSelfMerge = goerr.New("alert can not be merged into itself")
+
+	// ErrMergeCycle is returned when a merge makes a loop of merged alerts,
+	// or a loop is found in saved merge chains
+	ErrMergeCycle = goerr.New("merge creates a cycle")
+)
+
+// MergeNode is an alert in a merge tree with alerts merged into it
+type MergeNode struct {
+	Alert  *model.Alert
+	Merged []*MergeNode
+}
+
+// Merge consolidates source alert into target alert. If target is already
+// merged, source is merged into the root of the merge chain, i.e. the
+// unmerged alert at the end, instead. Alerts merged into source are also
+// re-pointed to the root so that merge chains do not grow. It returns ID of
+// the alert that source is merged into.
 func (u *UseCase) Merge(
 	ctx context.Context,
 	sourceID, targetID model.AlertID,
-) error {
-	// Get both alerts
+) (model.AlertID, error) {
+	if sourceID == targetID {
+		return "", goerr.Wrap(ErrSelfMerge, "failed to merge alert", goerr.Value("alertID", sourceID))
+	}
+
 	source, err := u.repo.GetAlert(ctx, sourceID)
 	if err != nil {
-		return goerr.Wrap(err, "failed to get source alert", goerr.Value("sourceID", sourceID))
+		return "", goerr.Wrap(err, "failed to get source alert", goerr.Value("sourceID", sourceID))
 	}
 
-	_, err = u.repo.GetAlert(ctx, targetID)
+	chain, err := u.mergeChain(ctx, targetID)
 	if err != nil {
-		return goerr.Wrap(err, "failed to get target alert", goerr.Value("targetID", targetID))
+		return "", goerr.Wrap(err, "failed to resolve target alert", goerr.Value("targetID", targetID))
 	}
 
-	// Mark source as merged to target
-	source.MergedTo = targetID
+	// Merging into an alert that is merged into source makes a loop
+	if slices.ContainsFunc(chain, func(a *model.Alert) bool { return a.ID == sourceID }) {
+		return "", goerr.Wrap(ErrMergeCycle, "target alert is merged into source alert",
+			goerr.Value("sourceID", sourceID),
+			goerr.Value("targetID", targetID),
+		)
+	}
+
+	root := chain[len(chain)-1]
+	if source.MergedTo == root.ID {
+		return root.ID, nil
+	}
+
+	// Mark source as merged to the root
+	source.MergedTo = root.ID
 
 	if err := u.repo.PutAlert(ctx, source); err != nil {
-		return goerr.Wrap(err, "failed to update source alert", goerr.Value("sourceID", sourceID))
+		return "", goerr.Wrap(err, "failed to update source alert", goerr.Value("sourceID", sourceID))
+	}
+
+	mergedInto := u.newActivity(sourceID, model.ActivityMergedInto)
+	mergedInto.RelatedID = string(root.ID)
+	mergedFrom := u.newActivity(root.ID, model.ActivityMergedFrom)
+	mergedFrom.RelatedID = string(sourceID)
+	if root.ID != targetID {
+		note := fmt.Sprintf("requested target %s is merged into %s", targetID, root.ID)
+		mergedInto.Note = note
+		mergedFrom.Note = note
+	}
+
+	repointed, err := u.repointMerged(ctx, sourceID, root.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := u.record(ctx, append([]*model.Activity{mergedInto, mergedFrom}, repointed...)...); err != nil {
+		return "", err
+	}
+
+	return root.ID, nil
+}
+
+// mergeChain returns the alert and alerts that it is merged into in order,
+// i.e. the last one is the root of the merge tree
+func (u *UseCase) mergeChain(ctx context.Context, alertID model.AlertID) ([]*model.Alert, error) {
+	var chain []*model.Alert
+	visited := make(map[model.AlertID]bool)
+
+	for id := alertID; id != ""; {
+		if visited[id] {
+			return nil, goerr.Wrap(ErrMergeCycle, "merge chain has a loop", goerr.Value("alertID", id))
+		}
+		visited[id] = true
+
+		a, err := u.repo.GetAlert(ctx, id)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get alert in merge chain", goerr.Value("alertID", id))
+		}
+		chain = append(chain, a)
+		id = a.MergedTo
+	}
+
+	return chain, nil
+}
+
+// mergedInto returns alerts merged directly into the alert
+func (u *UseCase) mergedInto(ctx context.Context, alertID model.AlertID) ([]*model.Alert, error) {
+	alerts, _, err := u.repo.ListAlerts(ctx, &repository.ListAlertsInput{
+		MergedTo: alertID,
+		Order:    repository.SortAsc,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list merged alerts", goerr.Value("alertID", alertID))
+	}
+	return alerts, nil
+}
+
+// repointMerged re-points all alerts merged into the parent, directly or
+// transitively, to the root. It returns activities to record.
+func (u *UseCase) repointMerged(ctx context.Context, parentID, rootID model.AlertID) ([]*model.Activity, error) {
+	var activities []*model.Activity
+	visited := map[model.AlertID]bool{parentID: true, rootID: true}
+
+	for queue := []model.AlertID{parentID}; len(queue) > 0; queue = queue[1:] {
+		children, err := u.mergedInto(ctx, queue[0])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range children {
+			if visited[child.ID] {
+				return nil, goerr.Wrap(ErrMergeCycle, "merged alerts have a loop", goerr.Value("alertID", child.ID))
+			}
+			visited[child.ID] = true
+			queue = append(queue, child.ID)
+
+			note := fmt.Sprintf("re-pointed because %s was merged into %s", child.MergedTo, rootID)
+			child.MergedTo = rootID
+			if err := u.repo.PutAlert(ctx, child); err != nil {
+				return nil, goerr.Wrap(err, "failed to update merged alert", goerr.Value("alertID", child.ID))
+			}
+
+			mergedInto := u.newActivity(child.ID, model.ActivityMergedInto)
+			mergedInto.RelatedID = string(rootID)
+			mergedInto.Note = note
+			mergedFrom := u.newActivity(rootID, model.ActivityMergedFrom)
+			mergedFrom.RelatedID = string(child.ID)
+			mergedFrom.Note = note
+			activities = append(activities, mergedInto, mergedFrom)
+		}
+	}
+
+	return activities, nil
+}
+
+// MergeTree returns the whole merge tree that the alert belongs to. Root of
+// the tree is the unmerged alert at the end of the merge chain of the alert.
+// It returns nil if the alert is not merged and has no merged alerts.
+func (u *UseCase) MergeTree(
+	ctx context.Context,
+	alertID model.AlertID,
+) (*MergeNode, error) {
+	chain, err := u.mergeChain(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := make(map[model.AlertID]bool)
+	tree, err := u.mergeTree(ctx, chain[len(chain)-1], visited)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 1 && len(tree.Merged) == 0 {
+		return nil, nil
+	}
+	return tree, nil
+}
+
+func (u *UseCase) mergeTree(ctx context.Context, a *model.Alert, visited map[model.AlertID]bool) (*MergeNode, error) {
+	if visited[a.ID] {
+		return nil, goerr.Wrap(ErrMergeCycle, "merged alerts have a loop", goerr.Value("alertID", a.ID))
+	}
+	visited[a.ID] = true
+
+	children, err := u.mergedInto(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &MergeNode{Alert: a}
+	for _, child := range children {
+		childNode, err := u.mergeTree(ctx, child, visited)
+		if err != nil {
+			return nil, err
+		}
+		node.Merged = append(node.Merged, childNode)
 	}
 
-	return nil
+	return node, nil
 }
@@ -0,0 +1,133 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+)
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, n int) (*alert.UseCase, repository.Repository, []model.AlertID) {
+		repo, err := repository.NewLocal(t.TempDir())
+		gt.NoError(t, err)
+
+		now := time.Now()
+		var ids []model.AlertID
+		for i := range n {
+			a := &model.Alert{
+				ID:        model.NewAlertID(),
+				Title:     "Suspicious login",
+				CreatedAt: now.Add(time.Duration(i) * time.Second),
+			}
+			gt.NoError(t, repo.PutAlert(ctx, a))
+			ids = append(ids, a.ID)
+		}
+		return alert.New(repo, nil), repo, ids
+	}
+
+	t.Run("self merge", func(t *testing.T) {
+		uc, _, ids := setup(t, 1)
+		_, err := uc.Merge(ctx, ids[0], ids[0])
+		gt.True(t, errors.Is(err, alert.ErrSelfMerge))
+	})
+
+	t.Run("follow chain to root", func(t *testing.T) {
+		uc, repo, ids := setup(t, 3)
+		mergedTo, err := uc.Merge(ctx, ids[1], ids[0])
+		gt.NoError(t, err)
+		gt.Equal(t, mergedTo, ids[0])
+
+		// ids[1] is merged, so ids[2] goes to ids[0]
+		mergedTo, err = uc.Merge(ctx, ids[2], ids[1])
+		gt.NoError(t, err)
+		gt.Equal(t, mergedTo, ids[0])
+
+		got, err := repo.GetAlert(ctx, ids[2])
+		gt.NoError(t, err)
+		gt.Equal(t, got.MergedTo, ids[0])
+	})
+
+	t.Run("reject cycle", func(t *testing.T) {
+		uc, _, ids := setup(t, 2)
+		_, err := uc.Merge(ctx, ids[1], ids[0])
+		gt.NoError(t, err)
+
+		_, err = uc.Merge(ctx, ids[0], ids[1])
+		gt.True(t, errors.Is(err, alert.ErrMergeCycle))
+	})
+
+	t.Run("re-point children", func(t *testing.T) {
+		uc, repo, ids := setup(t, 3)
+		_, err := uc.Merge(ctx, ids[2], ids[1])
+		gt.NoError(t, err)
+		_, err = uc.Merge(ctx, ids[1], ids[0])
+		gt.NoError(t, err)
+
+		got, err := repo.GetAlert(ctx, ids[2])
+		gt.NoError(t, err)
+		gt.Equal(t, got.MergedTo, ids[0])
+
+		activities, err := uc.Timeline(ctx, ids[2])
+		gt.NoError(t, err)
+		gt.A(t, activities).Length(2)
+		gt.Equal(t, activities[1].Type, model.ActivityMergedInto)
+		gt.Equal(t, activities[1].RelatedID, string(ids[0]))
+	})
+
+	t.Run("loop in saved chain", func(t *testing.T) {
+		uc, repo, ids := setup(t, 3)
+		for i, next := range []model.AlertID{ids[1], ids[0]} {
+			a, err := repo.GetAlert(ctx, ids[i])
+			gt.NoError(t, err)
+			a.MergedTo = next
+			gt.NoError(t, repo.PutAlert(ctx, a))
+		}
+
+		_, err := uc.Merge(ctx, ids[2], ids[0])
+		gt.True(t, errors.Is(err, alert.ErrMergeCycle))
+		_, err = uc.MergeTree(ctx, ids[0])
+		gt.True(t, errors.Is(err, alert.ErrMergeCycle))
+	})
+
+	t.Run("merge tree", func(t *testing.T) {
+		uc, repo, ids := setup(t, 4)
+		_, err := uc.Merge(ctx, ids[1], ids[0])
+		gt.NoError(t, err)
+		_, err = uc.Merge(ctx, ids[2], ids[0])
+		gt.NoError(t, err)
+
+		// Chain saved before merge resolution
+		a, err := repo.GetAlert(ctx, ids[3])
+		gt.NoError(t, err)
+		a.MergedTo = ids[2]
+		gt.NoError(t, repo.PutAlert(ctx, a))
+
+		tree, err := uc.MergeTree(ctx, ids[3])
+		gt.NoError(t, err)
+		gt.Equal(t, tree.Alert.ID, ids[0])
+		gt.A(t, tree.Merged).Length(2)
+		gt.Equal(t, tree.Merged[0].Alert.ID, ids[1])
+		gt.Equal(t, tree.Merged[1].Alert.ID, ids[2])
+		gt.A(t, tree.Merged[1].Merged).Length(1)
+		gt.Equal(t, tree.Merged[1].Merged[0].Alert.ID, ids[3])
+
+		// Alert without merge relation has no tree
+		uc, _, ids = setup(t, 1)
+		tree, err = uc.MergeTree(ctx, ids[0])
+		gt.NoError(t, err)
+		gt.True(t, tree == nil)
+	})
+
+	t.Run("unmerge not merged alert", func(t *testing.T) {
+		uc, _, ids := setup(t, 1)
+		gt.Error(t, uc.Unmerge(ctx, ids[0]))
+	})
+}
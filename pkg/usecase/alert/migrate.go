@@ -0,0 +1,55 @@
+package alert
+
+import (
+	"context"
+	"slices"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+)
+
+// migratePageSize is the number of alerts read at once by Migrate
+const migratePageSize = 100
+
+// Migrate backfills stored fields of alerts saved by older versions, so that
+// filters of List match them as shown. Status is filled with the status
+// derived from ResolvedAt, and Keywords are rebuilt by saving the alert.
+// Saving also writes MergedTo of unmerged alerts as an empty string, which
+// the merged filter queries. It returns alerts to be updated, and does not
+// save them if dryRun is true.
+func (u *UseCase) Migrate(ctx context.Context, dryRun bool) ([]*model.Alert, error) {
+	input := &repository.ListAlertsInput{Limit: migratePageSize}
+
+	var migrated []*model.Alert
+	for {
+		alerts, next, err := u.repo.ListAlerts(ctx, input)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list alerts to migrate")
+		}
+
+		for _, alert := range alerts {
+			if alert.Status != "" && slices.Equal(alert.Keywords, alert.BuildKeywords()) {
+				continue
+			}
+
+			alert.Status = alert.CurrentStatus()
+			migrated = append(migrated, alert)
+			if dryRun {
+				continue
+			}
+
+			// Keywords are refreshed by repository
+			if err := u.repo.PutAlert(ctx, alert); err != nil {
+				return nil, goerr.Wrap(err, "failed to save migrated alert", goerr.V("alertID", alert.ID))
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		input.PageToken = next
+	}
+
+	return migrated, nil
+}
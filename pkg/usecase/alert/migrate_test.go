@@ -0,0 +1,61 @@
+package alert_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	now := time.Now()
+	alerts := []*model.Alert{
+		{ID: "legacy-new", Title: "Login failure", CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "legacy-resolved", Title: "Login failure", ResolvedAt: &now, CreatedAt: now.Add(-time.Minute)},
+		{ID: "current", Title: "Login failure", Status: model.AlertStatusAcknowledged, CreatedAt: now},
+	}
+	for _, a := range alerts {
+		gt.NoError(t, repo.PutAlert(ctx, a))
+	}
+
+	uc := alert.New(repo, nil)
+	resolved := []model.AlertStatus{model.AlertStatusResolved}
+
+	t.Run("dry run", func(t *testing.T) {
+		migrated, err := uc.Migrate(ctx, true)
+		gt.NoError(t, err)
+		gt.A(t, migrated).Length(2)
+
+		got, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Statuses: resolved})
+		gt.NoError(t, err)
+		gt.A(t, got).Length(0)
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		migrated, err := uc.Migrate(ctx, false)
+		gt.NoError(t, err)
+		gt.A(t, migrated).Length(2)
+
+		got, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Statuses: resolved})
+		gt.NoError(t, err)
+		gt.A(t, got).Length(1)
+		gt.Equal(t, got[0].ID, "legacy-resolved")
+
+		a, err := repo.GetAlert(ctx, "legacy-new")
+		gt.NoError(t, err)
+		gt.Equal(t, a.Status, model.AlertStatusNew)
+
+		// Nothing is left to migrate
+		migrated, err = uc.Migrate(ctx, false)
+		gt.NoError(t, err)
+		gt.A(t, migrated).Length(0)
+	})
+}
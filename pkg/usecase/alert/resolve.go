@@ -23,7 +23,17 @@ func (u *UseCase) Resolve(
 		return err
 	}
 
+	if err := validateTransition(alert, model.AlertStatusResolved); err != nil {
+		return err
+	}
+
+	resolved := u.newActivity(alert.ID, model.ActivityResolved)
+	resolved.From = string(alert.CurrentStatus())
+	resolved.To = string(conclusion)
+	resolved.Note = note
+
 	now := time.Now()
+	alert.Status = model.AlertStatusResolved
 	alert.ResolvedAt = &now
 	alert.Conclusion = conclusion
 	alert.Note = note
@@ -32,5 +42,5 @@ func (u *UseCase) Resolve(
 		return err
 	}
 
-	return nil
+	return u.record(ctx, resolved)
 }
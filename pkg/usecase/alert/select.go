@@ -0,0 +1,167 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+)
+
+// defaultSelectLimit is the max number of alerts selected at once unless
+// Selector.Limit is set
+const defaultSelectLimit = 100
+
+var (
+	// ErrTooManyAlerts is returned when more alerts than the limit match a
+	// selector. Bulk operations are never applied to a part of matched alerts.
+	ErrTooManyAlerts = goerr.New("too many alerts selected")
+)
+
+// Selector selects target alerts of bulk operations. Exactly one of IDs,
+// Filter and SimilarTo must be set.
+type Selector struct {
+	// IDs selects the alerts as is
+	IDs []model.AlertID
+
+	// Filter selects alerts matching the same filters as List. Offset,
+	// Limit and PageToken are ignored.
+	Filter *repository.ListAlertsInput
+
+	// SimilarTo selects the alert and unmerged alerts within Threshold of
+	// cosine distance from it
+	SimilarTo model.AlertID
+	Threshold float64
+
+	// Limit is the max number of selected alerts. Default is 100.
+	Limit int
+}
+
+func (x *Selector) validate() error {
+	n := 0
+	if len(x.IDs) > 0 {
+		n++
+	}
+	if x.Filter != nil {
+		n++
+	}
+	if x.SimilarTo != "" {
+		n++
+	}
+	if n != 1 {
+		return goerr.New("exactly one of alert IDs, filter and similar alert is required to select alerts")
+	}
+
+	if x.SimilarTo != "" && x.Threshold <= 0 {
+		return goerr.New("threshold must be positive to select similar alerts", goerr.V("threshold", x.Threshold))
+	}
+	return nil
+}
+
+// Select returns alerts selected by the selector without duplicates. It
+// fails with ErrTooManyAlerts if more alerts than the limit are selected.
+func (u *UseCase) Select(ctx context.Context, sel *Selector) ([]*model.Alert, error) {
+	if err := sel.validate(); err != nil {
+		return nil, err
+	}
+
+	limit := sel.Limit
+	if limit <= 0 {
+		limit = defaultSelectLimit
+	}
+
+	var (
+		alerts []*model.Alert
+		err    error
+	)
+	switch {
+	case len(sel.IDs) > 0:
+		alerts, err = u.selectByIDs(ctx, sel.IDs)
+	case sel.Filter != nil:
+		alerts, err = u.selectByFilter(ctx, sel.Filter, limit)
+	default:
+		alerts, err = u.selectSimilar(ctx, sel.SimilarTo, sel.Threshold)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(alerts) > limit {
+		return nil, goerr.Wrap(ErrTooManyAlerts, "narrow down the selection or raise the limit",
+			goerr.V("limit", limit),
+			goerr.V("selected", len(alerts)),
+		)
+	}
+
+	return alerts, nil
+}
+
+func (u *UseCase) selectByIDs(ctx context.Context, ids []model.AlertID) ([]*model.Alert, error) {
+	var alerts []*model.Alert
+	seen := make(map[model.AlertID]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		a, err := u.repo.GetAlert(ctx, id)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get alert", goerr.V("alertID", id))
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
+
+// selectByFilter reads pages of the filter until one more alert than the
+// limit is found, so that the caller can detect excess
+func (u *UseCase) selectByFilter(ctx context.Context, filter *repository.ListAlertsInput, limit int) ([]*model.Alert, error) {
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	input := *filter
+	input.Offset = 0
+	input.PageToken = ""
+
+	var alerts []*model.Alert
+	for len(alerts) <= limit {
+		input.Limit = limit + 1 - len(alerts)
+		page, next, err := u.repo.ListAlerts(ctx, &input)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list alerts to select")
+		}
+		alerts = append(alerts, page...)
+
+		if next == "" {
+			break
+		}
+		input.PageToken = next
+	}
+	return alerts, nil
+}
+
+func (u *UseCase) selectSimilar(ctx context.Context, alertID model.AlertID, threshold float64) ([]*model.Alert, error) {
+	base, err := u.repo.GetAlert(ctx, alertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert to select similar alerts", goerr.V("alertID", alertID))
+	}
+	if len(base.Embedding) == 0 {
+		return nil, goerr.New("alert does not have an embedding vector", goerr.V("alertID", alertID))
+	}
+
+	similar, err := u.repo.SearchSimilarAlerts(ctx, base.Embedding, threshold)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to search similar alerts to select", goerr.V("alertID", alertID))
+	}
+
+	alerts := []*model.Alert{base}
+	for _, a := range similar {
+		if a.ID == base.ID || a.MergedTo != "" {
+			continue
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
@@ -0,0 +1,82 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+)
+
+func TestSelect(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	now := time.Now()
+	alerts := []*model.Alert{
+		{ID: "a1", Title: "Login failure", Status: model.AlertStatusNew, Embedding: []float32{1, 0}, CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: "a2", Title: "Login failure", Status: model.AlertStatusNew, Embedding: []float32{0.99, 0.01}, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "a3", Title: "Malware detected", Status: model.AlertStatusInvestigating, Embedding: []float32{0, 1}, CreatedAt: now.Add(-time.Minute)},
+		{ID: "a4", Title: "Login failure", Status: model.AlertStatusNew, Embedding: []float32{1, 0}, MergedTo: "a1", CreatedAt: now},
+	}
+	for _, a := range alerts {
+		gt.NoError(t, repo.PutAlert(ctx, a))
+	}
+
+	uc := alert.New(repo, nil)
+
+	ids := func(alerts []*model.Alert) []model.AlertID {
+		var ids []model.AlertID
+		for _, a := range alerts {
+			ids = append(ids, a.ID)
+		}
+		return ids
+	}
+
+	t.Run("by IDs", func(t *testing.T) {
+		got, err := uc.Select(ctx, &alert.Selector{IDs: []model.AlertID{"a3", "a1", "a3"}})
+		gt.NoError(t, err)
+		gt.Equal(t, ids(got), []model.AlertID{"a3", "a1"})
+
+		_, err = uc.Select(ctx, &alert.Selector{IDs: []model.AlertID{"missing"}})
+		gt.Error(t, err)
+	})
+
+	t.Run("by filter", func(t *testing.T) {
+		notMerged := false
+		got, err := uc.Select(ctx, &alert.Selector{Filter: &repository.ListAlertsInput{
+			Statuses: []model.AlertStatus{model.AlertStatusNew},
+			Merged:   &notMerged,
+		}})
+		gt.NoError(t, err)
+		gt.Equal(t, ids(got), []model.AlertID{"a2", "a1"})
+	})
+
+	t.Run("similar", func(t *testing.T) {
+		got, err := uc.Select(ctx, &alert.Selector{SimilarTo: "a1", Threshold: 0.1})
+		gt.NoError(t, err)
+		gt.Equal(t, ids(got), []model.AlertID{"a1", "a2"})
+	})
+
+	t.Run("too many alerts", func(t *testing.T) {
+		_, err := uc.Select(ctx, &alert.Selector{Filter: &repository.ListAlertsInput{}, Limit: 3})
+		gt.True(t, errors.Is(err, alert.ErrTooManyAlerts))
+
+		got, err := uc.Select(ctx, &alert.Selector{Filter: &repository.ListAlertsInput{}, Limit: 4})
+		gt.NoError(t, err)
+		gt.A(t, got).Length(4)
+	})
+
+	t.Run("exactly one selector", func(t *testing.T) {
+		_, err := uc.Select(ctx, &alert.Selector{})
+		gt.Error(t, err)
+
+		_, err = uc.Select(ctx, &alert.Selector{IDs: []model.AlertID{"a1"}, SimilarTo: "a1", Threshold: 0.1})
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,216 @@
+package alert
+
+import (
+	"context"
+	"slices"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+)
+
+var (
+	// ErrInvalidTransition is returned when an alert can not move to the requested status
+	ErrInvalidTransition = goerr.New("invalid status transition")
+)
+
+// transitions defines legal moves of alert status. Resolved alerts can be
+// resolved again to update their conclusion, and done alerts go back to new
+// only by reopen.
+var transitions = map[model.AlertStatus][]model.AlertStatus{
+	model.AlertStatusNew: {
+		model.AlertStatusAcknowledged,
+		model.AlertStatusInvestigating,
+		model.AlertStatusResolved,
+		model.AlertStatusClosed,
+	},
+	model.AlertStatusAcknowledged: {
+		model.AlertStatusInvestigating,
+		model.AlertStatusResolved,
+		model.AlertStatusClosed,
+	},
+	model.AlertStatusInvestigating: {
+		model.AlertStatusResolved,
+		model.AlertStatusClosed,
+	},
+	model.AlertStatusResolved: {
+		model.AlertStatusResolved,
+		model.AlertStatusNew,
+	},
+	model.AlertStatusClosed: {
+		model.AlertStatusNew,
+	},
+}
+
+// validateTransition checks if alert can move from its current status to next
+func validateTransition(alert *model.Alert, next model.AlertStatus) error {
+	if err := next.Validate(); err != nil {
+		return err
+	}
+
+	current := alert.CurrentStatus()
+	if !slices.Contains(transitions[current], next) {
+		return goerr.Wrap(ErrInvalidTransition, "alert can not move to the status",
+			goerr.V("alertID", alert.ID),
+			goerr.V("from", current),
+			goerr.V("to", next),
+		)
+	}
+	return nil
+}
+
+// Acknowledge marks an alert as seen by an analyst. If severity is not
+// empty, it overrides the severity given by triage.
+func (u *UseCase) Acknowledge(
+	ctx context.Context,
+	alertID model.AlertID,
+	severity model.Severity,
+) (*model.Alert, error) {
+	if severity != "" {
+		if err := severity.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	alert, err := u.repo.GetAlert(ctx, alertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert", goerr.Value("alertID", alertID))
+	}
+
+	if err := validateTransition(alert, model.AlertStatusAcknowledged); err != nil {
+		return nil, err
+	}
+
+	from := alert.CurrentStatus()
+	alert.Status = model.AlertStatusAcknowledged
+
+	var severityChanged *model.Activity
+	if severity != "" && severity != alert.Severity {
+		severityChanged = u.newActivity(alert.ID, model.ActivitySeverityChanged)
+		severityChanged.From = string(alert.Severity)
+		severityChanged.To = string(severity)
+		alert.Severity = severity
+	}
+
+	if err := u.repo.PutAlert(ctx, alert); err != nil {
+		return nil, goerr.Wrap(err, "failed to update alert", goerr.Value("alertID", alertID))
+	}
+
+	if err := u.record(ctx, u.statusChanged(alert, from), severityChanged); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// Assign sets the owner of an alert. Assigning a new or acknowledged alert
+// starts investigation of it.
+func (u *UseCase) Assign(
+	ctx context.Context,
+	alertID model.AlertID,
+	assignee string,
+) (*model.Alert, error) {
+	if assignee == "" {
+		return nil, goerr.New("assignee is required")
+	}
+
+	alert, err := u.repo.GetAlert(ctx, alertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert", goerr.Value("alertID", alertID))
+	}
+
+	current := alert.CurrentStatus()
+	if current.Done() {
+		return nil, goerr.Wrap(ErrInvalidTransition, "can not assign a done alert, reopen it first",
+			goerr.V("alertID", alertID),
+			goerr.V("status", current),
+		)
+	}
+
+	if current != model.AlertStatusInvestigating {
+		if err := validateTransition(alert, model.AlertStatusInvestigating); err != nil {
+			return nil, err
+		}
+		alert.Status = model.AlertStatusInvestigating
+	}
+
+	assigned := u.newActivity(alert.ID, model.ActivityAssigned)
+	assigned.From = alert.Assignee
+	assigned.To = assignee
+	alert.Assignee = assignee
+
+	if err := u.repo.PutAlert(ctx, alert); err != nil {
+		return nil, goerr.Wrap(err, "failed to update alert", goerr.Value("alertID", alertID))
+	}
+
+	if err := u.record(ctx, assigned, u.statusChanged(alert, current)); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// Close marks an alert as done without a conclusion, e.g. for noise or
+// duplicates that do not deserve an investigation
+func (u *UseCase) Close(
+	ctx context.Context,
+	alertID model.AlertID,
+	note string,
+) (*model.Alert, error) {
+	alert, err := u.repo.GetAlert(ctx, alertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert", goerr.Value("alertID", alertID))
+	}
+
+	if err := validateTransition(alert, model.AlertStatusClosed); err != nil {
+		return nil, err
+	}
+
+	from := alert.CurrentStatus()
+	alert.Status = model.AlertStatusClosed
+	if note != "" {
+		alert.Note = note
+	}
+
+	if err := u.repo.PutAlert(ctx, alert); err != nil {
+		return nil, goerr.Wrap(err, "failed to update alert", goerr.Value("alertID", alertID))
+	}
+
+	activity := u.statusChanged(alert, from)
+	activity.Note = note
+	if err := u.record(ctx, activity); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// Reopen moves a resolved or closed alert back to new. Conclusion and
+// resolution time are cleared, and the assignee is kept.
+func (u *UseCase) Reopen(
+	ctx context.Context,
+	alertID model.AlertID,
+) (*model.Alert, error) {
+	alert, err := u.repo.GetAlert(ctx, alertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert", goerr.Value("alertID", alertID))
+	}
+
+	if err := validateTransition(alert, model.AlertStatusNew); err != nil {
+		return nil, err
+	}
+
+	from := alert.CurrentStatus()
+	alert.Status = model.AlertStatusNew
+	alert.ResolvedAt = nil
+	alert.Conclusion = ""
+
+	if err := u.repo.PutAlert(ctx, alert); err != nil {
+		return nil, goerr.Wrap(err, "failed to update alert", goerr.Value("alertID", alertID))
+	}
+
+	if err := u.record(ctx, u.statusChanged(alert, from)); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
@@ -0,0 +1,123 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+)
+
+func setupAlert(t *testing.T, a *model.Alert) (*alert.UseCase, repository.Repository) {
+	t.Helper()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	a.ID = model.NewAlertID()
+	a.CreatedAt = time.Now()
+	gt.NoError(t, repo.PutAlert(context.Background(), a))
+
+	return alert.New(repo, nil, alert.WithActor("analyst")), repo
+}
+
+func TestStatusLifecycle(t *testing.T) {
+	ctx := context.Background()
+	a := &model.Alert{Title: "test", Status: model.AlertStatusNew, Severity: model.SeverityMedium}
+	uc, repo := setupAlert(t, a)
+
+	acked, err := uc.Acknowledge(ctx, a.ID, model.SeverityHigh)
+	gt.NoError(t, err)
+	gt.Equal(t, acked.Status, model.AlertStatusAcknowledged)
+	gt.Equal(t, acked.Severity, model.SeverityHigh)
+
+	// Acknowledging twice is not a legal transition
+	_, err = uc.Acknowledge(ctx, a.ID, "")
+	gt.True(t, errors.Is(err, alert.ErrInvalidTransition))
+
+	assigned, err := uc.Assign(ctx, a.ID, "analyst")
+	gt.NoError(t, err)
+	gt.Equal(t, assigned.Status, model.AlertStatusInvestigating)
+	gt.Equal(t, assigned.Assignee, "analyst")
+
+	gt.NoError(t, uc.Resolve(ctx, a.ID, model.ConclusionFalsePositive, "benign"))
+	resolved, err := repo.GetAlert(ctx, a.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, resolved.Status, model.AlertStatusResolved)
+	gt.V(t, resolved.ResolvedAt).NotNil()
+
+	// Done alerts can not be assigned or acknowledged until reopened
+	_, err = uc.Assign(ctx, a.ID, "another")
+	gt.True(t, errors.Is(err, alert.ErrInvalidTransition))
+	_, err = uc.Acknowledge(ctx, a.ID, "")
+	gt.True(t, errors.Is(err, alert.ErrInvalidTransition))
+
+	reopened, err := uc.Reopen(ctx, a.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, reopened.Status, model.AlertStatusNew)
+	gt.Equal(t, reopened.Conclusion, model.Conclusion(""))
+	gt.V(t, reopened.ResolvedAt).Nil()
+	gt.Equal(t, reopened.Assignee, "analyst")
+
+	closed, err := uc.Close(ctx, a.ID, "duplicated")
+	gt.NoError(t, err)
+	gt.Equal(t, closed.Status, model.AlertStatusClosed)
+
+	// Every change is recorded in the timeline
+	timeline, err := uc.Timeline(ctx, a.ID)
+	gt.NoError(t, err)
+	var types []model.ActivityType
+	for _, activity := range timeline {
+		gt.Equal(t, activity.Actor, "analyst")
+		types = append(types, activity.Type)
+	}
+	gt.Equal(t, types, []model.ActivityType{
+		model.ActivitySeverityChanged, // medium -> high
+		model.ActivityStatusChanged,   // new -> acknowledged
+		model.ActivityAssigned,
+		model.ActivityStatusChanged, // acknowledged -> investigating
+		model.ActivityResolved,
+		model.ActivityStatusChanged, // resolved -> new
+		model.ActivityStatusChanged, // new -> closed
+	})
+
+	// Closed alerts can not be resolved
+	err = uc.Resolve(ctx, a.ID, model.ConclusionTruePositive, "")
+	gt.True(t, errors.Is(err, alert.ErrInvalidTransition))
+}
+
+func TestStatusOfLegacyAlert(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unresolved alert is new", func(t *testing.T) {
+		a := &model.Alert{Title: "legacy"}
+		uc, _ := setupAlert(t, a)
+		gt.Equal(t, a.CurrentStatus(), model.AlertStatusNew)
+
+		acked, err := uc.Acknowledge(ctx, a.ID, "")
+		gt.NoError(t, err)
+		gt.Equal(t, acked.Status, model.AlertStatusAcknowledged)
+	})
+
+	t.Run("resolved alert can be reopened", func(t *testing.T) {
+		now := time.Now()
+		a := &model.Alert{Title: "legacy", ResolvedAt: &now, Conclusion: model.ConclusionUnaffected}
+		uc, _ := setupAlert(t, a)
+		gt.Equal(t, a.CurrentStatus(), model.AlertStatusResolved)
+
+		reopened, err := uc.Reopen(ctx, a.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, reopened.Status, model.AlertStatusNew)
+	})
+}
+
+func TestAcknowledgeInvalidSeverity(t *testing.T) {
+	a := &model.Alert{Title: "test", Status: model.AlertStatusNew}
+	uc, _ := setupAlert(t, a)
+
+	_, err := uc.Acknowledge(context.Background(), a.ID, model.Severity("urgent"))
+	gt.True(t, errors.Is(err, model.ErrInvalidSeverity))
+}
@@ -17,6 +17,13 @@ func (u *UseCase) Unmerge(
 		return goerr.Wrap(err, "failed to get alert", goerr.Value("alertID", alertID))
 	}
 
+	if alert.MergedTo == "" {
+		return goerr.New("alert is not merged", goerr.Value("alertID", alertID))
+	}
+
+	unmerged := u.newActivity(alertID, model.ActivityUnmerged)
+	unmerged.RelatedID = string(alert.MergedTo)
+
 	// Clear the merge reference
 	alert.MergedTo = ""
 
@@ -24,5 +31,5 @@ func (u *UseCase) Unmerge(
 		return goerr.Wrap(err, "failed to update alert", goerr.Value("alertID", alertID))
 	}
 
-	return nil
+	return u.record(ctx, unmerged)
 }
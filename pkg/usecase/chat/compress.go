@@ -120,7 +120,7 @@ func summarizeContents(ctx context.Context, gemini adapter.Gemini, contents []*g
 	}
 
 	// Pass contents with prompt to Gemini API
-	resp, err := gemini.GenerateContent(ctx, contentsWithPrompt, config)
+	resp, err := gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerCompress), contentsWithPrompt, config)
 	if err != nil {
 		return "", goerr.Wrap(err, "failed to generate summary")
 	}
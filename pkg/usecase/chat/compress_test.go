@@ -3,6 +3,7 @@ package chat_test
 import (
 	"context"
 	"errors"
+	"iter"
 	"testing"
 
 	"cloud.google.com/go/firestore"
@@ -15,6 +16,7 @@ import (
 type mockGemini struct {
 	generateFunc  func(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error)
 	embeddingFunc func(ctx context.Context, text string, dimensions int) (firestore.Vector32, error)
+	streamFunc    func(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error]
 }
 
 func (m *mockGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
@@ -24,6 +26,15 @@ func (m *mockGemini) GenerateContent(ctx context.Context, contents []*genai.Cont
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockGemini) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	if m.streamFunc != nil {
+		return m.streamFunc(ctx, contents, config)
+	}
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		yield(m.GenerateContent(ctx, contents, config))
+	}
+}
+
 func (m *mockGemini) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
 	return nil, errors.New("not implemented")
 }
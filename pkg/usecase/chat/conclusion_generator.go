@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/adapter"
 	"google.golang.org/genai"
 )
 
@@ -45,8 +46,15 @@ func (c *conclusionGenerator) Generate(ctx context.Context, plan *Plan, results
 		genai.NewContentFromText(buf.String(), genai.RoleUser),
 	}
 
-	// Generate conclusion
-	resp, err := c.gemini.GenerateContent(ctx, contents, config)
+	// Generate conclusion. Streaming is used if handler is set to render it incrementally
+	ctx = adapter.WithCaller(ctx, adapter.CallerConclusion)
+	var resp *genai.GenerateContentResponse
+	var err error
+	if c.onStream != nil {
+		resp, err = generateContentStream(ctx, c.gemini, contents, config, c.onStream)
+	} else {
+		resp, err = c.gemini.GenerateContent(ctx, contents, config)
+	}
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to generate conclusion")
 	}
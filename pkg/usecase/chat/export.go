@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"text/template"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"google.golang.org/genai"
+)
+
+// ExportFormat is the output format of an exported history
+type ExportFormat string
+
+const (
+	ExportFormatMarkdown ExportFormat = "md"
+	ExportFormatHTML     ExportFormat = "html"
+)
+
+// Validate checks if the export format is supported
+func (f ExportFormat) Validate() error {
+	switch f {
+	case ExportFormatMarkdown, ExportFormatHTML:
+		return nil
+	default:
+		return goerr.New("unsupported export format", goerr.V("format", f))
+	}
+}
+
+//go:embed report/history.md
+var reportMarkdownRaw string
+
+//go:embed report/history.html
+var reportHTMLRaw string
+
+var reportMarkdownTmpl = template.Must(template.New("history.md").Parse(reportMarkdownRaw))
+
+var reportHTMLTmpl = htmltemplate.Must(htmltemplate.New("history.html").Parse(reportHTMLRaw))
+
+// ExportInput contains parameters for exporting a conversation history
+type ExportInput struct {
+	Repo      repository.Repository
+	Storage   adapter.Storage
+	HistoryID model.HistoryID
+	Format    ExportFormat
+}
+
+// reportEntry is a single rendered element of a conversation
+type reportEntry struct {
+	Role   string // "user" or "model"
+	Text   string
+	Call   string // Name of called tool
+	Args   string // Arguments of tool call as indented JSON
+	Result string // Tool result as indented JSON
+}
+
+// Export renders a conversation history with its alert as an investigation
+// report and writes it to w. For a history of an incident, the incident is
+// rendered as an alert with attributes of all member alerts.
+func Export(ctx context.Context, w io.Writer, input ExportInput) error {
+	if err := input.Format.Validate(); err != nil {
+		return err
+	}
+
+	history, err := loadHistory(ctx, input.Repo, input.Storage, input.HistoryID)
+	if err != nil {
+		return goerr.Wrap(err, "failed to load history", goerr.V("history_id", input.HistoryID))
+	}
+
+	var alert *model.Alert
+	if history.IncidentID != "" {
+		incident, members, err := loadIncident(ctx, input.Repo, history.IncidentID)
+		if err != nil {
+			return err
+		}
+		alert = incidentAsAlert(incident, members)
+	} else {
+		alert, err = input.Repo.GetAlert(ctx, history.AlertID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to get alert", goerr.V("alert_id", history.AlertID))
+		}
+	}
+
+	data := map[string]any{
+		"Alert":   alert,
+		"History": history,
+		"Entries": buildReportEntries(history.Contents),
+	}
+
+	switch input.Format {
+	case ExportFormatHTML:
+		if err := reportHTMLTmpl.Execute(w, data); err != nil {
+			return goerr.Wrap(err, "failed to render HTML report")
+		}
+	default:
+		if err := reportMarkdownTmpl.Execute(w, data); err != nil {
+			return goerr.Wrap(err, "failed to render Markdown report")
+		}
+	}
+
+	return nil
+}
+
+// buildReportEntries flattens conversation contents into report entries.
+// Thought parts are omitted because they are not a part of the answer.
+func buildReportEntries(contents []*genai.Content) []*reportEntry {
+	var entries []*reportEntry
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+
+		role := content.Role
+		if role == "" {
+			role = genai.RoleUser
+		}
+
+		for _, part := range content.Parts {
+			switch {
+			case part.Thought:
+				continue
+
+			case part.FunctionCall != nil:
+				entries = append(entries, &reportEntry{
+					Role: role,
+					Call: part.FunctionCall.Name,
+					Args: toIndentedJSON(part.FunctionCall.Args),
+				})
+
+			case part.FunctionResponse != nil:
+				entries = append(entries, &reportEntry{
+					Role:   role,
+					Call:   part.FunctionResponse.Name,
+					Result: toIndentedJSON(part.FunctionResponse.Response),
+				})
+
+			case part.Text != "":
+				entries = append(entries, &reportEntry{
+					Role: role,
+					Text: part.Text,
+				})
+			}
+		}
+	}
+
+	return entries
+}
+
+func toIndentedJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,105 @@
+package chat_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/chat"
+	"google.golang.org/genai"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := repository.NewLocal(dir + "/db")
+	gt.NoError(t, err)
+	storage, err := adapter.NewFileStorage(dir + "/storage")
+	gt.NoError(t, err)
+
+	alert := &model.Alert{
+		ID:          model.NewAlertID(),
+		Title:       "Suspicious login",
+		Description: "Login from <unknown> location",
+		Attributes: []*model.Attribute{
+			{Key: "source_ip", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress},
+		},
+		CreatedAt: time.Now(),
+	}
+	gt.NoError(t, repo.PutAlert(ctx, alert))
+
+	history := &model.History{
+		ID:        model.NewHistoryID(),
+		Title:     "Login investigation",
+		AlertID:   alert.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	gt.NoError(t, repo.PutHistory(ctx, history))
+
+	contents := []*genai.Content{
+		genai.NewContentFromText("Who logged in?", genai.RoleUser),
+		{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "search_alerts", Args: map[string]any{"field": "user"}}}}},
+		{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{Name: "search_alerts", Response: map[string]any{"result": "alice"}}}}},
+		genai.NewContentFromText("The user is alice.", genai.RoleModel),
+	}
+	data, err := json.Marshal(contents)
+	gt.NoError(t, err)
+	w, err := storage.Put(ctx, "histories/"+string(history.ID)+".json")
+	gt.NoError(t, err)
+	_, err = w.Write(data)
+	gt.NoError(t, err)
+	gt.NoError(t, w.Close())
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, chat.Export(ctx, &buf, chat.ExportInput{
+			Repo:      repo,
+			Storage:   storage,
+			HistoryID: history.ID,
+			Format:    chat.ExportFormatMarkdown,
+		}))
+
+		out := buf.String()
+		gt.S(t, out).Contains("# Login investigation")
+		gt.S(t, out).Contains("Suspicious login")
+		gt.S(t, out).Contains("| source_ip | `192.0.2.1` | ip_address |")
+		gt.S(t, out).Contains("Who logged in?")
+		gt.S(t, out).Contains("**Tool call**: `search_alerts`")
+		gt.S(t, out).Contains("<summary>Result: search_alerts</summary>")
+		gt.S(t, out).Contains("The user is alice.")
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, chat.Export(ctx, &buf, chat.ExportInput{
+			Repo:      repo,
+			Storage:   storage,
+			HistoryID: history.ID,
+			Format:    chat.ExportFormatHTML,
+		}))
+
+		out := buf.String()
+		gt.S(t, out).Contains("<title>Login investigation</title>")
+		gt.S(t, out).Contains("Login from &lt;unknown&gt; location")
+		gt.S(t, out).Contains("<details>")
+		gt.S(t, out).Contains("The user is alice.")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.Error(t, chat.Export(ctx, &buf, chat.ExportInput{
+			Repo:      repo,
+			Storage:   storage,
+			HistoryID: history.ID,
+			Format:    "pdf",
+		}))
+	})
+}
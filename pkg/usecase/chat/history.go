@@ -89,7 +89,7 @@ func generateTitle(ctx context.Context, gemini adapter.Gemini, message string) (
 		genai.NewContentFromText(prompt, genai.RoleUser),
 	}
 
-	resp, err := gemini.GenerateContent(ctx, contents, nil)
+	resp, err := gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerTitle), contents, nil)
 	if err != nil {
 		return "", goerr.Wrap(err, "failed to generate title")
 	}
@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+)
+
+// incidentMember is a member alert of an incident rendered in the prompt
+type incidentMember struct {
+	Alert *model.Alert
+	Data  string // Raw alert data as indented JSON
+}
+
+// loadIncident retrieves an incident and its member alerts
+func loadIncident(ctx context.Context, repo repository.Repository, incidentID model.IncidentID) (*model.Incident, []*model.Alert, error) {
+	incident, err := repo.GetIncident(ctx, incidentID)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to get incident", goerr.V("incident_id", incidentID))
+	}
+
+	alerts := make([]*model.Alert, 0, len(incident.AlertIDs))
+	for _, alertID := range incident.AlertIDs {
+		alert, err := repo.GetAlert(ctx, alertID)
+		if err != nil {
+			return nil, nil, goerr.Wrap(err, "failed to get member alert", goerr.V("alert_id", alertID))
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return incident, alerts, nil
+}
+
+// incidentMembers marshals raw data of member alerts for the prompt
+func incidentMembers(alerts []*model.Alert) ([]*incidentMember, error) {
+	members := make([]*incidentMember, 0, len(alerts))
+	for _, alert := range alerts {
+		data, err := json.MarshalIndent(alert.Data, "", "  ")
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to marshal alert data", goerr.V("alert_id", alert.ID))
+		}
+		members = append(members, &incidentMember{Alert: alert, Data: string(data)})
+	}
+	return members, nil
+}
+
+// incidentAsAlert represents an incident as a single alert for prompts and
+// reports that are designed for an alert. Attributes of members are merged
+// without duplication and Data holds raw data of members by alert ID.
+func incidentAsAlert(incident *model.Incident, alerts []*model.Alert) *model.Alert {
+	data := make(map[string]any, len(alerts))
+	var attrs []*model.Attribute
+	seen := make(map[model.Attribute]struct{})
+	for _, alert := range alerts {
+		data[string(alert.ID)] = alert.Data
+		for _, attr := range alert.Attributes {
+			if attr == nil {
+				continue
+			}
+			if _, ok := seen[*attr]; ok {
+				continue
+			}
+			seen[*attr] = struct{}{}
+			attrs = append(attrs, attr)
+		}
+	}
+
+	return &model.Alert{
+		ID:          model.AlertID(incident.ID),
+		Title:       incident.Title,
+		Description: incident.Description,
+		Data:        data,
+		Attributes:  attrs,
+		Status:      incident.CurrentStatus(),
+		Assignee:    incident.Assignee,
+		CreatedAt:   incident.CreatedAt,
+	}
+}
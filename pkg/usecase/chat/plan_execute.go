@@ -99,7 +99,8 @@ type planGenerator struct {
 
 // conclusionGenerator generates final conclusions
 type conclusionGenerator struct {
-	gemini adapter.Gemini
+	gemini   adapter.Gemini
+	onStream StreamHandler
 }
 
 // newPlanGenerator creates a new plan generator
@@ -112,8 +113,8 @@ func newPlanGenerator(gemini adapter.Gemini, registry *tool.Registry) *planGener
 
 
 // newConclusionGenerator creates a new conclusion generator
-func newConclusionGenerator(gemini adapter.Gemini) *conclusionGenerator {
-	return &conclusionGenerator{gemini: gemini}
+func newConclusionGenerator(gemini adapter.Gemini, onStream StreamHandler) *conclusionGenerator {
+	return &conclusionGenerator{gemini: gemini, onStream: onStream}
 }
 
 // shouldUsePlanExecuteMode determines if plan & execute mode should be used
@@ -157,7 +158,7 @@ Respond with ONLY "yes" or "no".`
 	// Add current message
 	contents = append(contents, genai.NewContentFromText(message, genai.RoleUser))
 
-	resp, err := gemini.GenerateContent(ctx, contents, config)
+	resp, err := gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerModeJudge), contents, config)
 	if err != nil {
 		// On error, fall back to direct mode
 		return false
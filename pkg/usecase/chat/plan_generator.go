@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/adapter"
 	"github.com/m-mizutani/leveret/pkg/model"
 	"google.golang.org/genai"
 )
@@ -113,7 +114,7 @@ func (p *planGenerator) Generate(ctx context.Context, request string, alert *mod
 	contents = append(contents, genai.NewContentFromText(buf.String(), genai.RoleUser))
 
 	// Generate plan
-	resp, err := p.gemini.GenerateContent(ctx, contents, config)
+	resp, err := p.gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerPlan), contents, config)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to generate plan")
 	}
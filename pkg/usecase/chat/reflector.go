@@ -137,7 +137,7 @@ func reflect(ctx context.Context, gemini adapter.Gemini, registry *tool.Registry
 	}
 
 	// Generate reflection
-	resp, err := gemini.GenerateContent(ctx, contents, config)
+	resp, err := gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerReflection), contents, config)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to generate reflection")
 	}
@@ -22,17 +22,37 @@ type Session struct {
 	gemini   adapter.Gemini
 	storage  adapter.Storage
 	registry *tool.Registry
+	usage    *adapter.UsageTracker
+	onStream StreamHandler
+	actor    string
 
 	alertID         model.AlertID
 	alert           *model.Alert
 	history         *model.History
 	environmentInfo string
+
+	// baseUsage is token usage recorded in previous sessions of the loaded history
+	baseUsage *model.TokenUsage
+
+	// Set if the session is scoped to an incident. alert represents the
+	// incident for prompts designed for an alert.
+	incident *model.Incident
+	members  []*model.Alert
 }
 
 //go:embed prompt/session.md
 var sessionPromptRaw string
 
-var sessionPromptTmpl = template.Must(template.New("session").Parse(sessionPromptRaw))
+//go:embed prompt/incident.md
+var incidentPromptRaw string
+
+// sessionPromptTmpl renders incident information by the "incident" template
+// for sessions scoped to an incident
+var sessionPromptTmpl = func() *template.Template {
+	tmpl := template.Must(template.New("session").Parse(sessionPromptRaw))
+	template.Must(tmpl.New("incident").Parse(incidentPromptRaw))
+	return tmpl
+}()
 
 // NewInput contains parameters for creating a new chat session
 type NewInput struct {
@@ -41,14 +61,35 @@ type NewInput struct {
 	Storage         adapter.Storage
 	Registry        *tool.Registry
 	AlertID         model.AlertID
-	HistoryID       *model.HistoryID // Optional: specify to continue existing conversation
-	EnvironmentInfo string           // Optional: environment context for better analysis
+	IncidentID      model.IncidentID      // Optional: chat about an incident instead of AlertID
+	HistoryID       *model.HistoryID      // Optional: specify to continue existing conversation
+	EnvironmentInfo string                // Optional: environment context for better analysis
+	Usage           *adapter.UsageTracker // Optional: tracker wrapping Gemini to persist token usage on history
+	StreamHandler   StreamHandler         // Optional: receive response text incrementally with streaming generation
+	Actor           string                // Optional: analyst recorded in the alert timeline
 }
 
 func New(ctx context.Context, input NewInput) (*Session, error) {
-	alert, err := input.Repo.GetAlert(ctx, input.AlertID)
-	if err != nil {
-		return nil, goerr.Wrap(err, "failed to get alert")
+	var (
+		alert    *model.Alert
+		incident *model.Incident
+		members  []*model.Alert
+		err      error
+	)
+	if input.IncidentID != "" {
+		if input.AlertID != "" {
+			return nil, goerr.New("alert ID and incident ID can not be used together")
+		}
+		incident, members, err = loadIncident(ctx, input.Repo, input.IncidentID)
+		if err != nil {
+			return nil, err
+		}
+		alert = incidentAsAlert(incident, members)
+	} else {
+		alert, err = input.Repo.GetAlert(ctx, input.AlertID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get alert")
+		}
 	}
 
 	var history *model.History
@@ -58,6 +99,14 @@ func New(ctx context.Context, input NewInput) (*Session, error) {
 		if err != nil {
 			return nil, goerr.Wrap(err, "failed to load history")
 		}
+		if history.AlertID != input.AlertID || history.IncidentID != input.IncidentID {
+			return nil, goerr.New("history does not belong to the alert or incident",
+				goerr.V("history_id", history.ID),
+				goerr.V("history_alert_id", history.AlertID),
+				goerr.V("history_incident_id", history.IncidentID),
+				goerr.V("alert_id", input.AlertID),
+				goerr.V("incident_id", input.IncidentID))
+		}
 	} else {
 		// Create new history
 		history = &model.History{}
@@ -68,14 +117,26 @@ func New(ctx context.Context, input NewInput) (*Session, error) {
 		gemini:   input.Gemini,
 		storage:  input.Storage,
 		registry: input.Registry,
+		usage:    input.Usage,
+		onStream: input.StreamHandler,
+		actor:    input.Actor,
 
 		alertID:         input.AlertID,
 		alert:           alert,
 		history:         history,
 		environmentInfo: input.EnvironmentInfo,
+		baseUsage:       history.Usage,
+
+		incident: incident,
+		members:  members,
 	}, nil
 }
 
+// History returns the conversation history of the session
+func (s *Session) History() *model.History {
+	return s.history
+}
+
 func (s *Session) Send(ctx context.Context, message string) (*genai.GenerateContentResponse, error) {
 	// Generate title from first user input if this is a new history
 	if len(s.history.Contents) == 0 {
@@ -130,7 +191,7 @@ func (s *Session) Send(ctx context.Context, message string) (*genai.GenerateCont
 	var finalResp *genai.GenerateContentResponse
 
 	for i := 0; i < maxIterations; i++ {
-		resp, err := s.gemini.GenerateContent(ctx, s.history.Contents, config)
+		resp, err := s.generate(adapter.WithCaller(ctx, adapter.CallerChat), config)
 		if err != nil {
 			// Check if error is due to token limit exceeded
 			if isTokenLimitError(err) {
@@ -146,7 +207,7 @@ func (s *Session) Send(ctx context.Context, message string) (*genai.GenerateCont
 				s.history.Contents = compressedContents
 
 				// Save compressed history immediately
-				if saveErr := saveHistory(ctx, s.repo, s.storage, s.alertID, s.history); saveErr != nil {
+				if saveErr := s.saveHistory(ctx); saveErr != nil {
 					fmt.Printf("⚠️  警告: 圧縮した履歴の保存に失敗しました: %v\n", saveErr)
 				}
 
@@ -178,8 +239,8 @@ func (s *Session) Send(ctx context.Context, message string) (*genai.GenerateCont
 			s.history.Contents = append(s.history.Contents, candidate.Content)
 
 			for _, part := range candidate.Content.Parts {
-				// Display text content if present
-				if part.Text != "" {
+				// Display text content if present (already rendered in streaming mode)
+				if part.Text != "" && s.onStream == nil {
 					fmt.Printf("\n💭 %s\n\n", part.Text)
 				}
 
@@ -215,13 +276,55 @@ func (s *Session) Send(ctx context.Context, message string) (*genai.GenerateCont
 	}
 
 	// Save history to Cloud Storage and repository
-	if err := saveHistory(ctx, s.repo, s.storage, s.alertID, s.history); err != nil {
+	if err := s.saveHistory(ctx); err != nil {
 		return nil, goerr.Wrap(err, "failed to save history")
 	}
 
 	return finalResp, nil
 }
 
+// generate calls LLM with current history. Streaming generation is used if a
+// stream handler is set.
+func (s *Session) generate(ctx context.Context, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if s.onStream != nil {
+		return generateContentStream(ctx, s.gemini, s.history.Contents, config, s.onStream)
+	}
+	return s.gemini.GenerateContent(ctx, s.history.Contents, config)
+}
+
+// saveHistory updates token usage of the history and saves it
+func (s *Session) saveHistory(ctx context.Context) error {
+	if s.usage != nil {
+		usage := &model.TokenUsage{}
+		usage.Merge(s.baseUsage)
+		usage.Merge(s.usage.Usage())
+		s.history.Usage = usage
+	}
+
+	isNew := s.history.ID == ""
+	if isNew && s.incident != nil {
+		s.history.IncidentID = s.incident.ID
+	}
+	if err := saveHistory(ctx, s.repo, s.storage, s.alertID, s.history); err != nil {
+		return err
+	}
+
+	// Record the conversation in the alert or incident timeline when it is saved first
+	if isNew {
+		activity := model.NewActivity(s.alertID, model.ActivityChatStarted, s.actor)
+		if s.incident != nil {
+			activity = model.NewIncidentActivity(s.incident.ID, model.ActivityChatStarted, s.actor)
+		}
+		activity.RelatedID = string(s.history.ID)
+		activity.Note = s.history.Title
+		if err := s.repo.AddActivity(ctx, activity); err != nil {
+			return goerr.Wrap(err, "failed to record chat activity", goerr.V("history_id", s.history.ID))
+		}
+	}
+
+	return nil
+}
+
 func hasFunctionCall(resp *genai.GenerateContentResponse) bool {
 	for _, candidate := range resp.Candidates {
 		if candidate.Content != nil {
@@ -293,12 +396,21 @@ func (s *Session) buildSystemPrompt(ctx context.Context) (string, error) {
 		toolPrompts = s.registry.Prompts(ctx)
 	}
 
+	var members []*incidentMember
+	if s.incident != nil {
+		if members, err = incidentMembers(s.members); err != nil {
+			return "", err
+		}
+	}
+
 	// Execute template
 	var buf bytes.Buffer
 	if err := sessionPromptTmpl.Execute(&buf, map[string]any{
 		"AlertID":         s.alertID,
 		"Alert":           s.alert,
 		"AlertData":       string(alertData),
+		"Incident":        s.incident,
+		"Members":         members,
 		"EnvironmentInfo": s.environmentInfo,
 		"ToolPrompts":     toolPrompts,
 	}); err != nil {
@@ -313,7 +425,7 @@ func (s *Session) sendWithPlanExecute(ctx context.Context, message string) (*Pla
 
 	// Initialize plan & execute components
 	planGen := newPlanGenerator(s.gemini, s.registry)
-	conclusionGen := newConclusionGenerator(s.gemini)
+	conclusionGen := newConclusionGenerator(s.gemini, s.onStream)
 
 	// Step 1: Generate plan
 	fmt.Printf("\n📋 計画を生成中...\n")
@@ -334,6 +446,11 @@ func (s *Session) sendWithPlanExecute(ctx context.Context, message string) (*Pla
 	fmt.Printf("📝 結論を生成中...\n")
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
 
+	if s.onStream != nil {
+		// Header is rendered here because the conclusion is streamed before the response is returned
+		s.onStream(fmt.Sprintf("## 完了\n\n**目的**: %s\n\n", plan.Objective))
+	}
+
 	conclusion, err := conclusionGen.Generate(ctx, plan, results, reflections)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to generate conclusion")
@@ -357,7 +474,7 @@ func (s *Session) sendWithPlanExecute(ctx context.Context, message string) (*Pla
 	s.history.Contents = append(s.history.Contents, assistantContent)
 
 	// Save to history
-	if err := saveHistory(ctx, s.repo, s.storage, s.alertID, s.history); err != nil {
+	if err := s.saveHistory(ctx); err != nil {
 		fmt.Printf("⚠️  警告: 履歴の保存に失敗しました: %v\n", err)
 	}
 
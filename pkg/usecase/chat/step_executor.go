@@ -69,7 +69,7 @@ func runStepExecution(ctx context.Context, gemini adapter.Gemini, registry *tool
 		// Update system instruction with current iteration count
 		currentPrompt := fmt.Sprintf("%s\n\n**Current Status**: Tool call iteration %d/%d", buf.String(), i+1, maxIterations)
 		config.SystemInstruction = genai.NewContentFromText(currentPrompt, "")
-		resp, err := gemini.GenerateContent(ctx, contents, config)
+		resp, err := gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerStepExecution), contents, config)
 		if err != nil {
 			return &StepResult{
 				StepID:     step.ID,
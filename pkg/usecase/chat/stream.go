@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"context"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"google.golang.org/genai"
+)
+
+// StreamHandler receives text of a model response incrementally while it is
+// being generated
+type StreamHandler func(text string)
+
+// generateContentStream generates content with streaming and passes text
+// chunks to handler as they arrive. Chunks are assembled into a single
+// response so that callers can handle it in the same way as GenerateContent.
+// Function calls are never split across chunks, so they are collected as is.
+func generateContentStream(ctx context.Context, gemini adapter.Gemini, contents []*genai.Content, config *genai.GenerateContentConfig, handler StreamHandler) (*genai.GenerateContentResponse, error) {
+	var (
+		parts        []*genai.Part
+		text         strings.Builder
+		finishReason genai.FinishReason
+		usage        *genai.GenerateContentResponseUsageMetadata
+	)
+
+	// flushText appends buffered text as a single part
+	flushText := func() {
+		if text.Len() > 0 {
+			parts = append(parts, &genai.Part{Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for chunk, err := range gemini.GenerateContentStream(ctx, contents, config) {
+		if err != nil {
+			return nil, err
+		}
+
+		if chunk.UsageMetadata != nil {
+			usage = chunk.UsageMetadata
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+
+		candidate := chunk.Candidates[0]
+		if candidate.FinishReason != "" {
+			finishReason = candidate.FinishReason
+		}
+		if candidate.Content == nil {
+			continue
+		}
+
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.Thought:
+				// Thought summaries are not a part of the answer
+				continue
+
+			case part.Text != "" && len(part.ThoughtSignature) == 0:
+				text.WriteString(part.Text)
+				if handler != nil {
+					handler(part.Text)
+				}
+
+			default:
+				// Function calls and signed parts must be kept intact for the next turn
+				flushText()
+				if part.Text != "" && handler != nil {
+					handler(part.Text)
+				}
+				parts = append(parts, part)
+			}
+		}
+	}
+	flushText()
+
+	if len(parts) == 0 {
+		return nil, goerr.New("empty response from stream")
+	}
+
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{
+				Content: &genai.Content{
+					Role:  genai.RoleModel,
+					Parts: parts,
+				},
+				FinishReason: finishReason,
+			},
+		},
+		UsageMetadata: usage,
+	}, nil
+}
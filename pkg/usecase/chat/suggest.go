@@ -0,0 +1,177 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"text/template"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"google.golang.org/genai"
+)
+
+//go:embed prompt/suggest.md
+var suggestPromptRaw string
+
+var suggestPromptTmpl = template.Must(template.New("suggest").Parse(suggestPromptRaw))
+
+// maxSuggestToolDataLength bounds arguments and results of a tool call in the
+// suggestion prompt. Tool results are often large raw logs.
+const maxSuggestToolDataLength = 2000
+
+var (
+	// ErrNoHistory is returned when the alert has no chat history to suggest
+	// a resolution from
+	ErrNoHistory = goerr.New("no chat history of the alert")
+)
+
+// SuggestInput contains parameters for suggesting a resolution of an alert
+type SuggestInput struct {
+	Repo    repository.Repository
+	Storage adapter.Storage
+	Gemini  adapter.Gemini
+	AlertID model.AlertID
+}
+
+// Suggestion is a resolution of an alert proposed by LLM
+type Suggestion struct {
+	Conclusion model.Conclusion `json:"conclusion"`
+	Note       string           `json:"note"`
+}
+
+// suggestHistory is a chat history rendered in the suggestion prompt
+type suggestHistory struct {
+	Title     string
+	CreatedAt time.Time
+	Entries   []*reportEntry
+}
+
+// SuggestResolution proposes a conclusion and a note of the alert from its
+// chat histories, including histories of the incident that the alert
+// belongs to. The analyst is expected to review the suggestion before
+// resolving the alert.
+func SuggestResolution(ctx context.Context, input SuggestInput) (*Suggestion, error) {
+	alert, err := input.Repo.GetAlert(ctx, input.AlertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get alert", goerr.V("alert_id", input.AlertID))
+	}
+
+	histories, err := input.Repo.ListHistoryByAlert(ctx, input.AlertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list histories", goerr.V("alert_id", input.AlertID))
+	}
+
+	incidents, err := input.Repo.ListIncidentsByAlert(ctx, input.AlertID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list incidents of alert", goerr.V("alert_id", input.AlertID))
+	}
+	for _, incident := range incidents {
+		incidentHistories, err := input.Repo.ListHistoryByIncident(ctx, incident.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list histories", goerr.V("incident_id", incident.ID))
+		}
+		histories = append(histories, incidentHistories...)
+	}
+
+	if len(histories) == 0 {
+		return nil, goerr.Wrap(ErrNoHistory, "chat about the alert before suggesting resolution", goerr.V("alert_id", input.AlertID))
+	}
+
+	var rendered []*suggestHistory
+	for _, h := range histories {
+		history, err := loadHistory(ctx, input.Repo, input.Storage, h.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to load history", goerr.V("history_id", h.ID))
+		}
+
+		entries := buildReportEntries(history.Contents)
+		for _, entry := range entries {
+			entry.Args = truncateText(entry.Args, maxSuggestToolDataLength)
+			entry.Result = truncateText(entry.Result, maxSuggestToolDataLength)
+		}
+
+		rendered = append(rendered, &suggestHistory{
+			Title:     history.Title,
+			CreatedAt: history.CreatedAt,
+			Entries:   entries,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := suggestPromptTmpl.Execute(&buf, map[string]any{
+		"Alert":     alert,
+		"Histories": rendered,
+	}); err != nil {
+		return nil, goerr.Wrap(err, "failed to execute suggest prompt template")
+	}
+
+	thinkingBudget := int32(0)
+	config := &genai.GenerateContentConfig{
+		ResponseMIMEType: "application/json",
+		ThinkingConfig: &genai.ThinkingConfig{
+			IncludeThoughts: false,
+			ThinkingBudget:  &thinkingBudget,
+		},
+		ResponseSchema: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"conclusion": {
+					Type:        genai.TypeString,
+					Description: "Conclusion of the alert supported by the investigation",
+					Enum: []string{
+						string(model.ConclusionUnaffected),
+						string(model.ConclusionFalsePositive),
+						string(model.ConclusionTruePositive),
+					},
+				},
+				"note": {
+					Type:        genai.TypeString,
+					Description: "Summary of the conclusion and key evidence in 2-4 sentences",
+				},
+			},
+			Required: []string{"conclusion", "note"},
+		},
+	}
+
+	contents := []*genai.Content{
+		genai.NewContentFromText(buf.String(), genai.RoleUser),
+	}
+
+	resp, err := input.Gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerSuggest), contents, config)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to generate resolution suggestion")
+	}
+
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, goerr.New("invalid response structure from gemini", goerr.V("resp", resp))
+	}
+
+	rawJSON := resp.Candidates[0].Content.Parts[0].Text
+
+	var suggestion Suggestion
+	if err := json.Unmarshal([]byte(rawJSON), &suggestion); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal suggestion JSON", goerr.V("text", rawJSON))
+	}
+	if err := suggestion.Conclusion.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid suggested conclusion", goerr.V("conclusion", suggestion.Conclusion))
+	}
+	if suggestion.Note == "" {
+		return nil, goerr.New("suggested note is empty")
+	}
+
+	return &suggestion, nil
+}
+
+// truncateText cuts text longer than max runes
+func truncateText(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "\n... (truncated)"
+}
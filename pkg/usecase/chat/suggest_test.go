@@ -0,0 +1,100 @@
+package chat_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/chat"
+	"google.golang.org/genai"
+)
+
+func TestSuggestResolution(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := repository.NewLocal(dir + "/db")
+	gt.NoError(t, err)
+	storage, err := adapter.NewFileStorage(dir + "/storage")
+	gt.NoError(t, err)
+
+	alert := &model.Alert{
+		ID:        model.NewAlertID(),
+		Title:     "Suspicious login",
+		CreatedAt: time.Now(),
+	}
+	gt.NoError(t, repo.PutAlert(ctx, alert))
+
+	respond := func(text string) *mockGemini {
+		return &mockGemini{
+			generateFunc: func(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+				gt.True(t, config.ResponseSchema != nil)
+				gt.S(t, contents[0].Parts[0].Text).Contains("The login was from a VPN of alice.")
+				return &genai.GenerateContentResponse{
+					Candidates: []*genai.Candidate{
+						{Content: &genai.Content{Parts: []*genai.Part{{Text: text}}}},
+					},
+				}, nil
+			},
+		}
+	}
+
+	t.Run("no history", func(t *testing.T) {
+		_, err := chat.SuggestResolution(ctx, chat.SuggestInput{
+			Repo:    repo,
+			Storage: storage,
+			Gemini:  respond(`{}`),
+			AlertID: alert.ID,
+		})
+		gt.True(t, errors.Is(err, chat.ErrNoHistory))
+	})
+
+	history := &model.History{
+		ID:        model.NewHistoryID(),
+		Title:     "Login investigation",
+		AlertID:   alert.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	gt.NoError(t, repo.PutHistory(ctx, history))
+
+	contents := []*genai.Content{
+		genai.NewContentFromText("Who logged in?", genai.RoleUser),
+		genai.NewContentFromText("The login was from a VPN of alice.", genai.RoleModel),
+	}
+	data, err := json.Marshal(contents)
+	gt.NoError(t, err)
+	w, err := storage.Put(ctx, "histories/"+string(history.ID)+".json")
+	gt.NoError(t, err)
+	_, err = w.Write(data)
+	gt.NoError(t, err)
+	gt.NoError(t, w.Close())
+
+	t.Run("suggest", func(t *testing.T) {
+		suggestion, err := chat.SuggestResolution(ctx, chat.SuggestInput{
+			Repo:    repo,
+			Storage: storage,
+			Gemini:  respond(`{"conclusion": "false_positive", "note": "Login from the corporate VPN."}`),
+			AlertID: alert.ID,
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, suggestion.Conclusion, model.ConclusionFalsePositive)
+		gt.Equal(t, suggestion.Note, "Login from the corporate VPN.")
+	})
+
+	t.Run("invalid conclusion", func(t *testing.T) {
+		_, err := chat.SuggestResolution(ctx, chat.SuggestInput{
+			Repo:    repo,
+			Storage: storage,
+			Gemini:  respond(`{"conclusion": "benign", "note": "Login from the corporate VPN."}`),
+			AlertID: alert.ID,
+		})
+		gt.True(t, errors.Is(err, model.ErrInvalidConclusion))
+	})
+}
@@ -0,0 +1,194 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"github.com/m-mizutani/leveret/pkg/utils/logging"
+)
+
+// defaultMaxAttempts is the number of deliveries before a message is dead-lettered
+const defaultMaxAttempts = 5
+
+// Consumer pulls alert messages from a queue and ingests them with
+// at-least-once semantics. A message is acknowledged only after all of its
+// alerts are saved, so a crash or failure leads to redelivery. Alert IDs are
+// derived from the message ID, therefore redelivered messages do not create
+// duplicated alerts.
+type Consumer struct {
+	queue       adapter.Queue
+	ingest      *UseCase
+	workers     int
+	maxAttempts int
+	onResult    func(*adapter.Message, *Result)
+}
+
+// ConsumerOption is a functional option for Consumer
+type ConsumerOption func(*Consumer)
+
+// WithWorkers sets the number of messages processed in parallel
+func WithWorkers(n int) ConsumerOption {
+	return func(c *Consumer) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
+}
+
+// WithMaxAttempts sets the number of deliveries of a message before it is
+// moved to the dead-letter destination
+func WithMaxAttempts(n int) ConsumerOption {
+	return func(c *Consumer) {
+		if n > 0 {
+			c.maxAttempts = n
+		}
+	}
+}
+
+// WithOnResult sets a callback called with results of each input in a message
+func WithOnResult(fn func(*adapter.Message, *Result)) ConsumerOption {
+	return func(c *Consumer) {
+		c.onResult = fn
+	}
+}
+
+// NewConsumer creates a new Consumer
+func NewConsumer(queue adapter.Queue, ingest *UseCase, opts ...ConsumerOption) *Consumer {
+	c := &Consumer{
+		queue:       queue,
+		ingest:      ingest,
+		workers:     1,
+		maxAttempts: defaultMaxAttempts,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Run consumes messages until ctx is canceled or the queue is closed
+func (c *Consumer) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, c.workers)
+
+	for range c.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.work(ctx); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	// Return the first error if any worker failed
+	return <-errCh
+}
+
+func (c *Consumer) work(ctx context.Context) error {
+	for {
+		msg, err := c.queue.Receive(ctx)
+		if err != nil {
+			if errors.Is(err, adapter.ErrQueueClosed) || ctx.Err() != nil {
+				return nil
+			}
+			return goerr.Wrap(err, "failed to receive message")
+		}
+
+		if err := c.Handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// Handle processes a message and settles it with Ack, Nack or DeadLetter.
+// The returned error is only for failures of settling the message; errors of
+// ingestion are handled by redelivery.
+func (c *Consumer) Handle(ctx context.Context, msg *adapter.Message) error {
+	logger := logging.From(ctx).With("message_id", msg.ID, "attempt", msg.DeliveryAttempt)
+
+	inputs, err := c.decode(msg)
+	if err != nil {
+		// Malformed message never succeeds, so it does not need to be retried
+		logger.Warn("move malformed message to dead-letter", "error", err)
+		if err := c.queue.DeadLetter(ctx, msg, err); err != nil {
+			return goerr.Wrap(err, "failed to dead-letter message", goerr.V("id", msg.ID))
+		}
+		return nil
+	}
+
+	var failure error
+	for _, input := range inputs {
+		result := c.ingest.Process(ctx, input)
+		if c.onResult != nil {
+			c.onResult(msg, result)
+		}
+		if err := resultError(result); err != nil && failure == nil {
+			failure = err
+		}
+	}
+
+	if failure == nil {
+		return c.ack(ctx, msg)
+	}
+
+	if msg.DeliveryAttempt >= c.maxAttempts {
+		logger.Error("move message to dead-letter after retries", "error", failure)
+		if err := c.queue.DeadLetter(ctx, msg, failure); err != nil {
+			return goerr.Wrap(err, "failed to dead-letter message", goerr.V("id", msg.ID))
+		}
+		return nil
+	}
+
+	logger.Warn("failed to ingest message, will be redelivered", "error", failure)
+	if err := c.queue.Nack(ctx, msg); err != nil {
+		return goerr.Wrap(err, "failed to nack message", goerr.V("id", msg.ID))
+	}
+	return nil
+}
+
+// decode parses message data into inputs. Each input is keyed by the message
+// ID so that alert IDs are stable across redeliveries.
+func (c *Consumer) decode(msg *adapter.Message) ([]*Input, error) {
+	var inputs []*Input
+	for input, err := range Decode(bytes.NewReader(msg.Data), msg.ID) {
+		if err != nil {
+			return nil, err
+		}
+		input.Key = input.Source
+		inputs = append(inputs, input)
+	}
+	if len(inputs) == 0 {
+		return nil, goerr.New("no alert data in message", goerr.V("id", msg.ID))
+	}
+	return inputs, nil
+}
+
+func (c *Consumer) ack(ctx context.Context, msg *adapter.Message) error {
+	if err := c.queue.Ack(ctx, msg); err != nil {
+		return goerr.Wrap(err, "failed to ack message", goerr.V("id", msg.ID))
+	}
+	return nil
+}
+
+// resultError returns the first error in result
+func resultError(result *Result) error {
+	if result.Err != nil {
+		return result.Err
+	}
+	for _, a := range result.Alerts {
+		if a.Err != nil {
+			return a.Err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ingest_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/adapter"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/usecase/ingest"
+	"google.golang.org/genai"
+)
+
+// flakyGemini fails the first n calls of GenerateContent
+type flakyGemini struct {
+	mockGemini
+	failures atomic.Int32
+}
+
+func (m *flakyGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if m.failures.Add(-1) >= 0 {
+		return nil, errors.New("temporary failure")
+	}
+	return m.mockGemini.GenerateContent(ctx, contents, config)
+}
+
+func runConsumer(t *testing.T, queue *adapter.MemoryQueue, gemini adapter.Gemini, opts ...ingest.ConsumerOption) repository.Repository {
+	t.Helper()
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	queue.Close()
+	consumer := ingest.NewConsumer(queue, ingest.New(alert.New(repo, gemini)), opts...)
+	gt.NoError(t, consumer.Run(context.Background()))
+	gt.Equal(t, queue.Len(), 0)
+
+	return repo
+}
+
+func TestConsumer(t *testing.T) {
+	t.Run("redelivered message does not create duplicated alerts", func(t *testing.T) {
+		queue := adapter.NewMemoryQueue()
+		queue.Publish("msg-1", []byte(`[{"title": "a"}, {"title": "b"}]`), nil)
+		queue.Publish("msg-1", []byte(`[{"title": "a"}, {"title": "b"}]`), nil)
+		queue.Publish("msg-2", []byte(`{"title": "c"}`), nil)
+
+		repo := runConsumer(t, queue, &mockGemini{}, ingest.WithWorkers(2))
+
+		alerts, _, err := repo.ListAlerts(context.Background(), &repository.ListAlertsInput{Limit: 10})
+		gt.NoError(t, err)
+		gt.A(t, alerts).Length(3)
+		gt.A(t, queue.DeadLetters()).Length(0)
+	})
+
+	t.Run("failed message is retried", func(t *testing.T) {
+		queue := adapter.NewMemoryQueue()
+		queue.Publish("msg-1", []byte(`{"title": "a"}`), nil)
+
+		gemini := &flakyGemini{}
+		gemini.failures.Store(2)
+		repo := runConsumer(t, queue, gemini, ingest.WithMaxAttempts(3))
+
+		alerts, _, err := repo.ListAlerts(context.Background(), &repository.ListAlertsInput{Limit: 10})
+		gt.NoError(t, err)
+		gt.A(t, alerts).Length(1)
+		gt.A(t, queue.DeadLetters()).Length(0)
+	})
+
+	t.Run("message is dead-lettered after max attempts", func(t *testing.T) {
+		queue := adapter.NewMemoryQueue()
+		queue.Publish("msg-1", []byte(`{"title": "a"}`), nil)
+
+		gemini := &flakyGemini{}
+		gemini.failures.Store(10)
+		repo := runConsumer(t, queue, gemini, ingest.WithMaxAttempts(3))
+
+		alerts, _, err := repo.ListAlerts(context.Background(), &repository.ListAlertsInput{Limit: 10})
+		gt.NoError(t, err)
+		gt.A(t, alerts).Length(0)
+
+		dead := queue.DeadLetters()
+		gt.A(t, dead).Length(1)
+		gt.Equal(t, dead[0].Message.ID, "msg-1")
+		gt.Equal(t, dead[0].Message.DeliveryAttempt, 3)
+	})
+
+	t.Run("malformed message is dead-lettered without retry", func(t *testing.T) {
+		queue := adapter.NewMemoryQueue()
+		queue.Publish("msg-1", []byte(`{broken`), nil)
+
+		runConsumer(t, queue, &mockGemini{})
+
+		dead := queue.DeadLetters()
+		gt.A(t, dead).Length(1)
+		gt.Equal(t, dead[0].Message.DeliveryAttempt, 1)
+	})
+}
@@ -0,0 +1,254 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/workflow"
+)
+
+// Action is the outcome of an alert in ingestion
+type Action string
+
+const (
+	ActionAccepted  Action = "accepted"
+	ActionNotified  Action = "notified"
+	ActionDiscarded Action = "discarded"
+	ActionErrored   Action = "errored"
+)
+
+// Input is a single raw alert document to ingest
+type Input struct {
+	Source string // Where the input came from, e.g. "events.jsonl#3"
+	Data   any
+
+	// Key is an optional idempotency key such as a message ID. If set, alerts
+	// get IDs derived from it and processing the same input again does not
+	// create duplicated alerts.
+	Key string
+}
+
+// insertOptions returns options of alert.UseCase.Insert for idx-th alert of the input
+func (x *Input) insertOptions(idx int) []alert.InsertOption {
+	if x.Key == "" {
+		return nil
+	}
+	return []alert.InsertOption{
+		alert.WithAlertID(model.NewAlertIDFromKey(fmt.Sprintf("%s/%d", x.Key, idx))),
+	}
+}
+
+// AlertResult is the outcome of an alert generated from an input
+type AlertResult struct {
+	Title    string
+	Action   Action
+	Severity string
+	Note     string
+	AlertID  model.AlertID // Set if the alert was saved
+	MergedTo model.AlertID // Set if the alert was grouped into an existing alert
+	Err      error
+}
+
+// Result is the outcome of an input
+type Result struct {
+	Source   string
+	Alerts   []*AlertResult
+	Rejected bool  // No alert was generated by ingest policy
+	Err      error // Failed to read or process the input
+}
+
+// Summary counts outcomes of ingestion. Inputs rejected by ingest policy are
+// counted as discarded.
+type Summary struct {
+	Inputs    int
+	Accepted  int
+	Notified  int
+	Discarded int
+	Errored   int
+}
+
+func (s *Summary) add(result *Result) {
+	s.Inputs++
+	switch {
+	case result.Err != nil:
+		s.Errored++
+	case result.Rejected:
+		s.Discarded++
+	}
+
+	for _, a := range result.Alerts {
+		switch a.Action {
+		case ActionAccepted:
+			s.Accepted++
+		case ActionNotified:
+			s.Notified++
+		case ActionDiscarded:
+			s.Discarded++
+		case ActionErrored:
+			s.Errored++
+		}
+	}
+}
+
+// UseCase ingests raw alert data through the workflow and saves alerts
+type UseCase struct {
+	alerts      *alert.UseCase
+	engine      *workflow.Engine
+	concurrency int
+}
+
+// Option is a functional option for UseCase
+type Option func(*UseCase)
+
+// WithEngine sets the workflow engine. Without it, every input is inserted
+// as an alert directly.
+func WithEngine(engine *workflow.Engine) Option {
+	return func(uc *UseCase) {
+		uc.engine = engine
+	}
+}
+
+// WithConcurrency sets the number of inputs processed in parallel
+func WithConcurrency(n int) Option {
+	return func(uc *UseCase) {
+		if n > 0 {
+			uc.concurrency = n
+		}
+	}
+}
+
+// New creates a new ingest UseCase instance
+func New(alerts *alert.UseCase, opts ...Option) *UseCase {
+	uc := &UseCase{
+		alerts:      alerts,
+		concurrency: 1,
+	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+// Process ingests a single input. Errors are reported in Result instead of
+// returned so that a batch can continue with other inputs.
+func (u *UseCase) Process(ctx context.Context, input *Input) *Result {
+	result := &Result{Source: input.Source}
+
+	if u.engine == nil {
+		a, err := u.alerts.Insert(ctx, input.Data, input.insertOptions(0)...)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.Alerts = append(result.Alerts, &AlertResult{
+			Title:    a.Title,
+			Action:   ActionAccepted,
+			AlertID:  a.ID,
+			MergedTo: a.MergedTo,
+		})
+		return result
+	}
+
+	workflowResults, err := u.engine.Execute(ctx, input.Data)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if len(workflowResults) == 0 {
+		result.Rejected = true
+		return result
+	}
+
+	for i, wr := range workflowResults {
+		ar := &AlertResult{
+			Title:  wr.Alert.Title,
+			Action: ActionAccepted,
+		}
+		if wr.Triage != nil {
+			ar.Severity = wr.Triage.Severity
+			ar.Note = wr.Triage.Note
+			switch model.TriageAction(wr.Triage.Action) {
+			case model.TriageActionDiscard:
+				ar.Action = ActionDiscarded
+			case model.TriageActionNotify:
+				ar.Action = ActionNotified
+			}
+		}
+		result.Alerts = append(result.Alerts, ar)
+
+		if ar.Action == ActionDiscarded {
+			continue
+		}
+
+		opts := input.insertOptions(i)
+		if u.engine.HasGroupPolicy() {
+			// Group policy decides grouping in addition to similarity
+			opts = append(opts, alert.WithGroupHook(u.groupHook))
+		}
+		newAlert, err := u.alerts.InsertIngested(ctx, wr.Alert, opts...)
+		if err != nil {
+			ar.Action = ActionErrored
+			ar.Err = err
+			continue
+		}
+		ar.AlertID = newAlert.ID
+		ar.MergedTo = newAlert.MergedTo
+	}
+
+	return result
+}
+
+// groupHook evaluates group policy of the workflow engine
+func (u *UseCase) groupHook(ctx context.Context, a *model.Alert, candidate *alert.GroupCandidate) (bool, error) {
+	return u.engine.Group(ctx, a, candidate.Alert, candidate.Distance)
+}
+
+// Run ingests inputs with bounded concurrency. onResult is called for each
+// input as soon as it is processed. Calls of onResult are serialized.
+func (u *UseCase) Run(ctx context.Context, inputs iter.Seq2[*Input, error], onResult func(*Result)) *Summary {
+	var (
+		summary Summary
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+	)
+	sem := make(chan struct{}, u.concurrency)
+
+	report := func(result *Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		summary.add(result)
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	for input, err := range inputs {
+		if err != nil {
+			report(&Result{Err: err})
+			continue
+		}
+		if ctx.Err() != nil {
+			report(&Result{Source: input.Source, Err: ctx.Err()})
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(input *Input) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			report(u.Process(ctx, input))
+		}(input)
+	}
+
+	wg.Wait()
+	return &summary
+}
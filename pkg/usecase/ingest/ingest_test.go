@@ -0,0 +1,122 @@
+package ingest_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
+	"github.com/m-mizutani/leveret/pkg/repository"
+	"github.com/m-mizutani/leveret/pkg/usecase/alert"
+	"github.com/m-mizutani/leveret/pkg/usecase/ingest"
+	"github.com/m-mizutani/leveret/pkg/workflow"
+	"google.golang.org/genai"
+)
+
+// mockGemini returns a fixed alert summary
+type mockGemini struct{}
+
+func (m *mockGemini) GenerateContent(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	text := `{"title": "summarized", "description": "summarized alert", "attributes": []}`
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(text, genai.RoleModel)}},
+	}, nil
+}
+
+func (m *mockGemini) GenerateContentStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		yield(m.GenerateContent(ctx, contents, config))
+	}
+}
+
+func (m *mockGemini) CreateChat(ctx context.Context, config *genai.GenerateContentConfig, history []*genai.Content) (*genai.Chat, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockGemini) Embedding(ctx context.Context, text string, dimensions int) (firestore.Vector32, error) {
+	return make(firestore.Vector32, dimensions), nil
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := t.TempDir()
+	gt.NoError(t, os.WriteFile(filepath.Join(policyDir, "ingest.rego"), []byte(`package ingest
+
+alert contains {
+	"title": input.title,
+	"description": "",
+	"attributes": [],
+} if {
+	input.title != ""
+}
+`), 0644))
+	gt.NoError(t, os.WriteFile(filepath.Join(policyDir, "triage.rego"), []byte(`package triage
+
+default action = "accept"
+default severity = "medium"
+default note = ""
+
+action = "discard" if {
+	contains(input.alert.title, "maintenance")
+}
+
+action = "notify" if {
+	contains(input.alert.title, "notice")
+}
+`), 0644))
+
+	engine, err := workflow.New(ctx, policyDir, nil, nil)
+	gt.NoError(t, err)
+
+	repo, err := repository.NewLocal(t.TempDir())
+	gt.NoError(t, err)
+
+	uc := ingest.New(alert.New(repo, &mockGemini{}),
+		ingest.WithEngine(engine),
+		ingest.WithConcurrency(2),
+	)
+
+	input := strings.Join([]string{
+		`{"title": "suspicious login"}`,
+		`{"title": "scheduled maintenance"}`,
+		`{"title": "notice of new device"}`,
+		`{"title": ""}`,
+		`{"title": "another login"}`,
+	}, "\n")
+
+	var results []*ingest.Result
+	summary := uc.Run(ctx, ingest.Decode(strings.NewReader(input), "test"), func(r *ingest.Result) {
+		results = append(results, r)
+	})
+
+	gt.A(t, results).Length(5)
+	gt.Equal(t, summary.Inputs, 5)
+	gt.Equal(t, summary.Accepted, 2)
+	gt.Equal(t, summary.Notified, 1)
+	gt.Equal(t, summary.Discarded, 2)
+	gt.Equal(t, summary.Errored, 0)
+
+	alerts, _, err := repo.ListAlerts(ctx, &repository.ListAlertsInput{Limit: 10})
+	gt.NoError(t, err)
+	gt.A(t, alerts).Length(3)
+
+	// Title by ingest policy and triage result are stored as they are
+	titles := map[string]*model.Alert{}
+	for _, a := range alerts {
+		titles[a.Title] = a
+	}
+	login, ok := titles["suspicious login"]
+	gt.True(t, ok)
+	gt.Equal(t, login.Severity, model.SeverityMedium)
+	gt.Equal(t, login.TriageAction, model.TriageActionAccept)
+	notice, ok := titles["notice of new device"]
+	gt.True(t, ok)
+	gt.Equal(t, notice.TriageAction, model.TriageActionNotify)
+}
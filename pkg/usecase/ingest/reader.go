@@ -0,0 +1,132 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// inputExtensions are file extensions read from a directory
+var inputExtensions = []string{".json", ".jsonl", ".ndjson"}
+
+// ReadPath returns inputs read from path. "-" reads from stdin and a
+// directory reads all JSON files directly under it in name order.
+func ReadPath(path string, stdin io.Reader) iter.Seq2[*Input, error] {
+	return func(yield func(*Input, error) bool) {
+		if path == "-" {
+			for input, err := range Decode(stdin, "stdin") {
+				if !yield(input, err) {
+					return
+				}
+			}
+			return
+		}
+
+		files, err := listFiles(path)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, file := range files {
+			if !readFile(file, yield) {
+				return
+			}
+		}
+	}
+}
+
+// readFile yields inputs in file and returns false if iteration is stopped
+func readFile(path string, yield func(*Input, error) bool) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return yield(nil, goerr.Wrap(err, "failed to open input file", goerr.V("path", path)))
+	}
+	defer f.Close()
+
+	for input, err := range Decode(f, path) {
+		if !yield(input, err) {
+			return false
+		}
+	}
+	return true
+}
+
+func listFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to stat input path", goerr.V("path", path))
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read input directory", goerr.V("path", path))
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		for _, e := range inputExtensions {
+			if ext == e {
+				files = append(files, filepath.Join(path, entry.Name()))
+				break
+			}
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Decode returns inputs in r. r can contain a single JSON document,
+// concatenated documents such as NDJSON, or a JSON array whose elements are
+// handled as separate inputs. Decoding stops at the first malformed document
+// because the rest of the stream can not be located reliably.
+func Decode(r io.Reader, source string) iter.Seq2[*Input, error] {
+	return func(yield func(*Input, error) bool) {
+		dec := json.NewDecoder(r)
+		n := 0
+		next := func(data any) bool {
+			n++
+			return yield(&Input{Source: fmt.Sprintf("%s#%d", source, n), Data: data}, nil)
+		}
+
+		for {
+			var v any
+			if err := dec.Decode(&v); err != nil {
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				yield(nil, goerr.Wrap(err, "failed to decode input", goerr.V("source", source), goerr.V("index", n+1)))
+				return
+			}
+
+			if items, ok := v.([]any); ok {
+				for _, item := range items {
+					if !next(item) {
+						return
+					}
+				}
+				continue
+			}
+
+			if !next(v) {
+				return
+			}
+		}
+	}
+}
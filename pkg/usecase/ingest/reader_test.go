@@ -0,0 +1,86 @@
+package ingest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/usecase/ingest"
+)
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		sources []string
+		wantErr bool
+	}{
+		{
+			name:    "single document",
+			input:   `{"id": 1}`,
+			sources: []string{"src#1"},
+		},
+		{
+			name:    "NDJSON",
+			input:   "{\"id\": 1}\n{\"id\": 2}\n\n{\"id\": 3}\n",
+			sources: []string{"src#1", "src#2", "src#3"},
+		},
+		{
+			name:    "JSON array",
+			input:   `[{"id": 1}, {"id": 2}]`,
+			sources: []string{"src#1", "src#2"},
+		},
+		{
+			name:    "malformed document after valid one",
+			input:   "{\"id\": 1}\n{invalid",
+			sources: []string{"src#1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sources []string
+			var gotErr bool
+			for input, err := range ingest.Decode(strings.NewReader(tc.input), "src") {
+				if err != nil {
+					gotErr = true
+					continue
+				}
+				sources = append(sources, input.Source)
+			}
+
+			gt.Equal(t, gotErr, tc.wantErr)
+			gt.A(t, sources).Length(len(tc.sources))
+			for i := range tc.sources {
+				gt.Equal(t, sources[i], tc.sources[i])
+			}
+		})
+	}
+}
+
+func TestReadPath(t *testing.T) {
+	dir := t.TempDir()
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, "b.jsonl"), []byte("{\"id\": 2}\n{\"id\": 3}\n"), 0644))
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"id": 1}`), 0644))
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte(`{"id": 9}`), 0644))
+
+	var ids []float64
+	for input, err := range ingest.ReadPath(dir, nil) {
+		gt.NoError(t, err)
+		ids = append(ids, input.Data.(map[string]any)["id"].(float64))
+	}
+	gt.A(t, ids).Length(3)
+	gt.Equal(t, ids[0], 1.0)
+	gt.Equal(t, ids[2], 3.0)
+
+	// stdin
+	var count int
+	for _, err := range ingest.ReadPath("-", strings.NewReader(`[{"id": 1}, {"id": 2}]`)) {
+		gt.NoError(t, err)
+		count++
+	}
+	gt.Equal(t, count, 2)
+}
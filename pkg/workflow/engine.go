@@ -6,6 +6,8 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"text/template"
 
 	"github.com/m-mizutani/goerr/v2"
@@ -23,10 +25,12 @@ var enrichPromptRaw string
 var enrichPromptTmpl = template.Must(template.New("enrich").Parse(enrichPromptRaw))
 
 // regoPrintHook implements print.Hook interface for Rego print() statements
-type regoPrintHook struct{}
+type regoPrintHook struct {
+	w io.Writer
+}
 
 func (h *regoPrintHook) Print(ctx print.Context, message string) error {
-	fmt.Printf("   [Rego] %s\n", message)
+	fmt.Fprintf(h.w, "   [Rego] %s\n", message)
 	return nil
 }
 
@@ -35,33 +39,53 @@ type Engine struct {
 	ingestPolicy *rego.PreparedEvalQuery
 	enrichPolicy *rego.PreparedEvalQuery
 	triagePolicy *rego.PreparedEvalQuery
+	groupPolicy  *rego.PreparedEvalQuery
 
 	gemini   adapter.Gemini
 	registry *tool.Registry
+	output   io.Writer
+}
+
+// Option is a functional option for Engine
+type Option func(*Engine)
+
+// WithOutput sets the writer of progress messages and Rego print()
+// statements. Default is stdout. Use io.Discard to make the engine quiet,
+// e.g. when alerts are processed concurrently.
+func WithOutput(w io.Writer) Option {
+	return func(e *Engine) {
+		e.output = w
+	}
 }
 
 // New creates a new workflow engine
-func New(ctx context.Context, policyDir string, gemini adapter.Gemini, registry *tool.Registry) (*Engine, error) {
-	ingest, enrich, triage, err := loadPolicies(ctx, policyDir)
+func New(ctx context.Context, policyDir string, gemini adapter.Gemini, registry *tool.Registry, opts ...Option) (*Engine, error) {
+	ingest, enrich, triage, group, err := loadPolicies(ctx, policyDir)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Engine{
+	e := &Engine{
 		ingestPolicy: ingest,
 		enrichPolicy: enrich,
 		triagePolicy: triage,
+		groupPolicy:  group,
 		gemini:       gemini,
 		registry:     registry,
-	}, nil
+		output:       os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
 }
 
 // Execute runs the workflow on the raw alert data
 func (e *Engine) Execute(ctx context.Context, rawData any) ([]*WorkflowResult, error) {
 	// Phase 1: Ingest
-	fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("📥 INGEST PHASE\n")
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(e.output, "\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(e.output, "📥 INGEST PHASE\n")
+	fmt.Fprintf(e.output, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	ingestResult, err := e.runIngest(ctx, rawData)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to run ingest phase")
@@ -69,28 +93,28 @@ func (e *Engine) Execute(ctx context.Context, rawData any) ([]*WorkflowResult, e
 
 	// If no alerts generated, return empty result
 	if len(ingestResult.Alert) == 0 {
-		fmt.Printf("❌ No alerts generated (rejected by policy)\n\n")
+		fmt.Fprintf(e.output, "❌ No alerts generated (rejected by policy)\n\n")
 		return nil, nil
 	}
 
-	fmt.Printf("✅ Generated %d alert(s)\n", len(ingestResult.Alert))
+	fmt.Fprintf(e.output, "✅ Generated %d alert(s)\n", len(ingestResult.Alert))
 	for i, alert := range ingestResult.Alert {
-		fmt.Printf("   %d. %s\n", i+1, alert.Title)
+		fmt.Fprintf(e.output, "   %d. %s\n", i+1, alert.Title)
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(e.output, "\n")
 
 	// Process each alert
 	results := make([]*WorkflowResult, 0, len(ingestResult.Alert))
 	for i, alert := range ingestResult.Alert {
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("📋 ALERT %d/%d: %s\n", i+1, len(ingestResult.Alert), alert.Title)
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		fmt.Fprintf(e.output, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		fmt.Fprintf(e.output, "📋 ALERT %d/%d: %s\n", i+1, len(ingestResult.Alert), alert.Title)
+		fmt.Fprintf(e.output, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		result, err := e.processAlert(ctx, alert, rawData)
 		if err != nil {
 			return nil, goerr.Wrap(err, "failed to process alert")
 		}
 		results = append(results, result)
-		fmt.Printf("\n")
+		fmt.Fprintf(e.output, "\n")
 	}
 
 	return results, nil
@@ -111,31 +135,38 @@ func (e *Engine) processAlert(ctx context.Context, ingestedAlert *IngestedAlert,
 
 	// Phase 2: Enrich
 	if e.enrichPolicy != nil {
-		fmt.Printf("\n🔍 ENRICH PHASE\n")
+		fmt.Fprintf(e.output, "\n🔍 ENRICH PHASE\n")
 		enrichResult, enrichExecution, err := e.runEnrich(ctx, alert)
 		if err != nil {
 			return nil, goerr.Wrap(err, "failed to run enrich phase")
 		}
 		if len(enrichResult.Prompt) == 0 {
-			fmt.Printf("   ℹ️  No enrichment prompts generated\n")
+			fmt.Fprintf(e.output, "   ℹ️  No enrichment prompts generated\n")
 		} else {
-			fmt.Printf("   ✅ Executed %d enrichment task(s)\n", len(enrichResult.Prompt))
+			fmt.Fprintf(e.output, "   ✅ Executed %d enrichment task(s)\n", len(enrichResult.Prompt))
 			for i, exec := range enrichExecution.Result {
-				fmt.Printf("      %d. %s: ", i+1, exec.ID)
+				fmt.Fprintf(e.output, "      %d. %s: ", i+1, exec.ID)
 				if len(exec.Result) > 60 {
-					fmt.Printf("%s...\n", exec.Result[:60])
+					fmt.Fprintf(e.output, "%s...\n", exec.Result[:60])
 				} else {
-					fmt.Printf("%s\n", exec.Result)
+					fmt.Fprintf(e.output, "%s\n", exec.Result)
 				}
 			}
 		}
 		result.EnrichResult = enrichResult
 		result.EnrichExecution = enrichExecution
+
+		for _, exec := range enrichExecution.Result {
+			alert.Enrichments = append(alert.Enrichments, &model.Enrichment{
+				ID:     exec.ID,
+				Result: exec.Result,
+			})
+		}
 	}
 
 	// Phase 3: Triage
 	if e.triagePolicy != nil {
-		fmt.Printf("\n⚖️  TRIAGE PHASE\n")
+		fmt.Fprintf(e.output, "\n⚖️  TRIAGE PHASE\n")
 		triageResult, err := e.runTriage(ctx, alert, result.EnrichExecution)
 		if err != nil {
 			return nil, goerr.Wrap(err, "failed to run triage phase")
@@ -166,12 +197,16 @@ func (e *Engine) processAlert(ctx context.Context, ingestedAlert *IngestedAlert,
 			severityEmoji = "ℹ️"
 		}
 
-		fmt.Printf("   %s Action: %s\n", actionEmoji, triageResult.Action)
-		fmt.Printf("   %s Severity: %s\n", severityEmoji, triageResult.Severity)
+		fmt.Fprintf(e.output, "   %s Action: %s\n", actionEmoji, triageResult.Action)
+		fmt.Fprintf(e.output, "   %s Severity: %s\n", severityEmoji, triageResult.Severity)
 		if triageResult.Note != "" {
-			fmt.Printf("   📝 Note: %s\n", triageResult.Note)
+			fmt.Fprintf(e.output, "   📝 Note: %s\n", triageResult.Note)
 		}
 		result.Triage = triageResult
+
+		alert.Severity = model.Severity(triageResult.Severity)
+		alert.TriageAction = model.TriageAction(triageResult.Action)
+		alert.TriageNote = triageResult.Note
 	}
 
 	return result, nil
@@ -183,7 +218,7 @@ func (e *Engine) runIngest(ctx context.Context, rawData any) (*IngestResult, err
 		return &IngestResult{Alert: nil}, nil
 	}
 
-	rs, err := e.ingestPolicy.Eval(ctx, rego.EvalInput(rawData), rego.EvalPrintHook(&regoPrintHook{}))
+	rs, err := e.ingestPolicy.Eval(ctx, rego.EvalInput(rawData), rego.EvalPrintHook(&regoPrintHook{w: e.output}))
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to evaluate ingest policy")
 	}
@@ -238,7 +273,7 @@ func (e *Engine) runEnrich(ctx context.Context, alert *model.Alert) (*EnrichResu
 		"attributes":  alert.Attributes,
 	}
 
-	rs, err := e.enrichPolicy.Eval(ctx, rego.EvalInput(input), rego.EvalPrintHook(&regoPrintHook{}))
+	rs, err := e.enrichPolicy.Eval(ctx, rego.EvalInput(input), rego.EvalPrintHook(&regoPrintHook{w: e.output}))
 	if err != nil {
 		return nil, nil, goerr.Wrap(err, "failed to evaluate enrich policy")
 	}
@@ -283,7 +318,7 @@ func (e *Engine) runEnrich(ctx context.Context, alert *model.Alert) (*EnrichResu
 	}
 
 	for i, prompt := range enrichResult.Prompt {
-		fmt.Printf("   🤖 Task %d/%d: %s\n", i+1, len(enrichResult.Prompt), prompt.ID)
+		fmt.Fprintf(e.output, "   🤖 Task %d/%d: %s\n", i+1, len(enrichResult.Prompt), prompt.ID)
 		result, err := e.executePrompt(ctx, prompt, alert)
 		if err != nil {
 			return nil, nil, goerr.Wrap(err, "failed to execute prompt", goerr.Value("prompt_id", prompt.ID))
@@ -342,7 +377,7 @@ func (e *Engine) executePrompt(ctx context.Context, prompt AgentPrompt, alert *m
 	var finalResult string
 
 	for i := 0; i < maxIterations; i++ {
-		resp, err := e.gemini.GenerateContent(ctx, contents, config)
+		resp, err := e.gemini.GenerateContent(adapter.WithCaller(ctx, adapter.CallerEnrich), contents, config)
 		if err != nil {
 			return "", goerr.Wrap(err, "failed to generate content")
 		}
@@ -412,22 +447,22 @@ func (e *Engine) executeTool(ctx context.Context, funcCall genai.FunctionCall) (
 		return nil, goerr.New("tool registry not available")
 	}
 
-	fmt.Printf("      🔧 Tool: %s\n", funcCall.Name)
+	fmt.Fprintf(e.output, "      🔧 Tool: %s\n", funcCall.Name)
 
 	// Execute the tool via registry
 	resp, err := e.registry.Execute(ctx, funcCall)
 	if err != nil {
-		fmt.Printf("      ❌ Tool execution failed: %v\n", err)
+		fmt.Fprintf(e.output, "      ❌ Tool execution failed: %v\n", err)
 		return nil, goerr.Wrap(err, "tool execution failed")
 	}
 
 	// Check if response contains error
 	if errMsg, ok := resp.Response["error"].(string); ok {
-		fmt.Printf("         ⚠️  Error: %s\n", errMsg)
+		fmt.Fprintf(e.output, "         ⚠️  Error: %s\n", errMsg)
 		return resp, nil
 	}
 
-	fmt.Printf("         ✓ Success\n")
+	fmt.Fprintf(e.output, "         ✓ Success\n")
 	return resp, nil
 }
 
@@ -566,7 +601,7 @@ func (e *Engine) runTriage(ctx context.Context, alert *model.Alert, enrichExecut
 		"enrich": enrichResults,
 	}
 
-	rs, err := e.triagePolicy.Eval(ctx, rego.EvalInput(input), rego.EvalPrintHook(&regoPrintHook{}))
+	rs, err := e.triagePolicy.Eval(ctx, rego.EvalInput(input), rego.EvalPrintHook(&regoPrintHook{w: e.output}))
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to evaluate triage policy")
 	}
@@ -591,6 +626,57 @@ func (e *Engine) runTriage(ctx context.Context, alert *model.Alert, enrichExecut
 	return result, nil
 }
 
+// HasGroupPolicy returns true if group policy is defined
+func (e *Engine) HasGroupPolicy() bool {
+	return e.groupPolicy != nil
+}
+
+// Group evaluates group policy to decide if a new alert should be grouped
+// into an existing open alert. It returns false if group policy is not
+// defined. Policy gets the new alert, the candidate, cosine distance between
+// them and elapsed seconds from creation of the candidate, and sets "match".
+func (e *Engine) Group(ctx context.Context, alert, candidate *model.Alert, distance float64) (bool, error) {
+	if e.groupPolicy == nil {
+		return false, nil
+	}
+
+	input := map[string]any{
+		"alert": map[string]any{
+			"id":          alert.ID,
+			"title":       alert.Title,
+			"description": alert.Description,
+			"attributes":  alert.Attributes,
+			"severity":    alert.Severity,
+		},
+		"candidate": map[string]any{
+			"id":          candidate.ID,
+			"title":       candidate.Title,
+			"description": candidate.Description,
+			"attributes":  candidate.Attributes,
+			"severity":    candidate.Severity,
+			"status":      candidate.CurrentStatus(),
+		},
+		"distance": distance,
+		"elapsed":  alert.CreatedAt.Sub(candidate.CreatedAt).Seconds(),
+	}
+
+	rs, err := e.groupPolicy.Eval(ctx, rego.EvalInput(input), rego.EvalPrintHook(&regoPrintHook{w: e.output}))
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to evaluate group policy")
+	}
+
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return false, nil
+	}
+
+	data, ok := rs[0].Expressions[0].Value.(map[string]any)
+	if !ok {
+		return false, nil
+	}
+	match, _ := data["match"].(bool)
+	return match, nil
+}
+
 // Helper functions
 func getString(m map[string]any, key string) string {
 	if v, ok := m[key]; ok {
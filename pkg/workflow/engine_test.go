@@ -1,12 +1,15 @@
 package workflow_test
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/leveret/pkg/model"
 	"github.com/m-mizutani/leveret/pkg/workflow"
 )
 
@@ -30,7 +33,8 @@ alert contains {
 	gt.NoError(t, os.WriteFile(filepath.Join(tmpDir, "ingest.rego"), []byte(ingestPolicy), 0644))
 
 	// Create engine
-	engine, err := workflow.New(ctx, tmpDir, nil, nil)
+	var output bytes.Buffer
+	engine, err := workflow.New(ctx, tmpDir, nil, nil, workflow.WithOutput(&output))
 	gt.NoError(t, err)
 
 	// Test data that matches policy
@@ -42,6 +46,7 @@ alert contains {
 	gt.NoError(t, err)
 	gt.Equal(t, len(results), 1)
 	gt.Equal(t, results[0].Alert.Title, "Test Alert")
+	gt.S(t, output.String()).Contains("Generated 1 alert(s)")
 
 	// Test data that doesn't match policy
 	data2 := map[string]any{
@@ -103,6 +108,8 @@ severity = "critical" if {
 	gt.Equal(t, len(results), 1)
 	gt.Equal(t, results[0].Triage.Severity, "critical")
 	gt.Equal(t, results[0].Triage.Action, "accept")
+	gt.Equal(t, results[0].Alert.Severity, model.SeverityCritical)
+	gt.Equal(t, results[0].Alert.TriageAction, model.TriageActionAccept)
 
 	// Test maintenance alert (should be discarded)
 	data2 := map[string]any{
@@ -135,3 +142,56 @@ func TestNoPolicyFiles(t *testing.T) {
 	// Without ingest policy, no alerts should be generated
 	gt.Equal(t, len(results), 0)
 }
+
+func TestGroupPolicy(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	groupPolicy := `package group
+
+default match = false
+
+match if {
+	some a in input.alert.attributes
+	some c in input.candidate.attributes
+	a.type == "ip_address"
+	a.value == c.value
+	input.elapsed < 3600
+}
+`
+	gt.NoError(t, os.WriteFile(filepath.Join(tmpDir, "group.rego"), []byte(groupPolicy), 0644))
+
+	engine, err := workflow.New(ctx, tmpDir, nil, nil)
+	gt.NoError(t, err)
+	gt.True(t, engine.HasGroupPolicy())
+
+	now := time.Now()
+	alert := &model.Alert{
+		Attributes: []*model.Attribute{{Key: "src", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress}},
+		CreatedAt:  now,
+	}
+	candidate := &model.Alert{
+		ID:         model.NewAlertID(),
+		Attributes: []*model.Attribute{{Key: "dst", Value: "192.0.2.1", Type: model.AttributeTypeIPAddress}},
+		CreatedAt:  now.Add(-time.Minute),
+	}
+
+	matched, err := engine.Group(ctx, alert, candidate, 0.8)
+	gt.NoError(t, err)
+	gt.True(t, matched)
+
+	candidate.CreatedAt = now.Add(-2 * time.Hour)
+	matched, err = engine.Group(ctx, alert, candidate, 0.8)
+	gt.NoError(t, err)
+	gt.False(t, matched)
+
+	t.Run("no group policy", func(t *testing.T) {
+		engine, err := workflow.New(ctx, t.TempDir(), nil, nil)
+		gt.NoError(t, err)
+		gt.False(t, engine.HasGroupPolicy())
+
+		matched, err := engine.Group(ctx, alert, candidate, 0)
+		gt.NoError(t, err)
+		gt.False(t, matched)
+	})
+}
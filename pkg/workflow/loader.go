@@ -6,51 +6,67 @@ import (
 	"path/filepath"
 
 	"github.com/m-mizutani/goerr/v2"
+	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/rego"
 )
 
 // loadPolicies loads all Rego files from policyDir and prepares queries for each phase
-func loadPolicies(ctx context.Context, policyDir string) (ingest, enrich, triage *rego.PreparedEvalQuery, err error) {
+func loadPolicies(ctx context.Context, policyDir string) (ingest, enrich, triage, group *rego.PreparedEvalQuery, err error) {
 	// Read all .rego files from the directory
 	files, err := filepath.Glob(filepath.Join(policyDir, "*.rego"))
 	if err != nil {
-		return nil, nil, nil, goerr.Wrap(err, "failed to glob policy files")
+		return nil, nil, nil, nil, goerr.Wrap(err, "failed to glob policy files")
 	}
 
 	if len(files) == 0 {
 		// No policy files found, return nil for all phases
-		return nil, nil, nil, nil
+		return nil, nil, nil, nil, nil
 	}
 
 	// Load all policy files as modules
 	modules := make([]func(*rego.Rego), 0, len(files))
+	hasGroup := false
 	for _, file := range files {
 		data, err := os.ReadFile(file)
 		if err != nil {
-			return nil, nil, nil, goerr.Wrap(err, "failed to read policy file", goerr.Value("path", file))
+			return nil, nil, nil, nil, goerr.Wrap(err, "failed to read policy file", goerr.Value("path", file))
 		}
 		modules = append(modules, rego.Module(file, string(data)))
+
+		// Group policy is optional and evaluated for every ingested alert,
+		// so it is prepared only if a module declares the package
+		if m, err := ast.ParseModule(file, string(data)); err == nil && m.Package.Path.String() == "data.group" {
+			hasGroup = true
+		}
 	}
 
 	// Prepare query for ingest phase
 	ingest, err = prepareQuery(ctx, modules, "data.ingest")
 	if err != nil {
-		return nil, nil, nil, goerr.Wrap(err, "failed to prepare ingest query")
+		return nil, nil, nil, nil, goerr.Wrap(err, "failed to prepare ingest query")
 	}
 
 	// Prepare query for enrich phase
 	enrich, err = prepareQuery(ctx, modules, "data.enrich")
 	if err != nil {
-		return nil, nil, nil, goerr.Wrap(err, "failed to prepare enrich query")
+		return nil, nil, nil, nil, goerr.Wrap(err, "failed to prepare enrich query")
 	}
 
 	// Prepare query for triage phase
 	triage, err = prepareQuery(ctx, modules, "data.triage")
 	if err != nil {
-		return nil, nil, nil, goerr.Wrap(err, "failed to prepare triage query")
+		return nil, nil, nil, nil, goerr.Wrap(err, "failed to prepare triage query")
+	}
+
+	// Prepare query for grouping of new alerts into existing ones
+	if hasGroup {
+		group, err = prepareQuery(ctx, modules, "data.group")
+		if err != nil {
+			return nil, nil, nil, nil, goerr.Wrap(err, "failed to prepare group query")
+		}
 	}
 
-	return ingest, enrich, triage, nil
+	return ingest, enrich, triage, group, nil
 }
 
 // prepareQuery prepares a Rego query with all loaded modules